@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type CacheCmd struct {
+	List  CacheListCmd  `cmd:"" help:"List cached uploads"`
+	Purge CachePurgeCmd `cmd:"" help:"Remove cached uploads"`
+}
+
+type CacheListCmd struct{}
+
+func (c *CacheListCmd) Run() error {
+	cache, err := newUploadCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "NO_CACHE_ENTRIES_FOUND")
+		return nil
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(os.Stdout, "BACKEND=%s\n", entry.Backend)
+		_, _ = fmt.Fprintf(os.Stdout, "SHA256=%s\n", entry.SHA256)
+		_, _ = fmt.Fprintf(os.Stdout, "FILE=%s\n", entry.File)
+		_, _ = fmt.Fprintf(os.Stdout, "URL=%s\n", redactURL(entry.URL))
+		_, _ = fmt.Fprintf(os.Stdout, "CREATED_AT=%s\n", entry.CreatedAt.Format(time.RFC3339))
+		_, _ = fmt.Fprintf(os.Stdout, "EXPIRES_AT=%s\n", entry.ExpiresAt.Format(time.RFC3339))
+		_, _ = fmt.Fprintf(os.Stdout, "EXPIRED=%t\n", entry.Expired(now))
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	return nil
+}
+
+type CachePurgeCmd struct {
+	Expired bool `help:"Only remove entries that are expired or about to expire"`
+}
+
+func (c *CachePurgeCmd) Run() error {
+	cache, err := newUploadCache()
+	if err != nil {
+		return err
+	}
+
+	removed, err := cache.Purge(c.Expired)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "PURGED_ENTRIES=%d\n", removed)
+	return nil
+}
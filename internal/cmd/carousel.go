@@ -6,26 +6,122 @@ import (
 	"os"
 	"strings"
 
+	"github.com/alecthomas/kong"
+
 	"github.com/mahmoudashraf93/poster/internal/config"
 	"github.com/mahmoudashraf93/poster/internal/graph"
+	"github.com/mahmoudashraf93/poster/internal/media"
 	"github.com/mahmoudashraf93/poster/internal/upload"
 )
 
 type CarouselCmd struct {
-	Files   []string `help:"Local media files" type:"existingfile"`
-	Caption string   `help:"Post caption" short:"c"`
+	Files         []string `aliases:"items" help:"Local media files and/or public HTTPS URLs, in carousel order"`
+	Caption       string   `help:"Post caption" short:"c"`
+	Tags          []string `name:"tag" sep:"none" help:"Tag a user on an image: <file>=@username:x,y with x,y in 0-1 (repeatable)"`
+	LocationID    string   `help:"Facebook Place ID to tag (see: poster location search)"`
+	ProductTags   []string `name:"product-tag" sep:"none" help:"Tag a catalog product on an image: <file>=<product_id>:x,y with x,y in 0-1 (repeatable)"`
+	AltTexts      []string `name:"alt" sep:"none" help:"Alt text for an image: <file>=<text> (repeatable)"`
+	Collaborators []string `name:"collaborator" help:"Invite a collaborator by username (repeatable, max 3)"`
+
+	FirstCommentFlags `embed:""`
+	FitFlags          `embed:""`
+	ImageFlags        `embed:""`
+	UploadFlags       `embed:""`
+}
+
+// Instagram's limits on the number of items in a carousel.
+const (
+	minCarouselItems = 2
+	maxCarouselItems = 10
+)
+
+type carouselItem struct {
+	source string // local path or remote URL
+	path   string // expanded local path the user gave, before preparing
+	remote bool
+	media  media.Info
+	probed *upload.URLInfo // set when a remote URL was probed for its type
 }
 
 func (c *CarouselCmd) Run(root *RootFlags) error {
 	if len(c.Files) == 0 {
 		return usage("provide at least one --files entry")
 	}
+	if len(c.Files) < minCarouselItems || len(c.Files) > maxCarouselItems {
+		return usage(fmt.Sprintf("a carousel needs %d to %d items, got %d", minCarouselItems, maxCarouselItems, len(c.Files)))
+	}
+
+	firstComment, err := c.FirstCommentFlags.resolve()
+	if err != nil {
+		return err
+	}
 
 	cfg, err := config.LoadWithProfile(root.Profile)
 	if err != nil {
 		return err
 	}
 
+	images, err := newImagePipeline(cfg, &c.ImageFlags, &c.FitFlags)
+	if err != nil {
+		return err
+	}
+
+	defer images.release()
+
+	ctx := context.Background()
+	items, err := resolveCarouselItems(ctx, images, c.Files)
+	if err != nil {
+		return err
+	}
+
+	isVideo := make([]bool, len(items))
+	for i, item := range items {
+		isVideo[i] = item.media.IsVideo()
+	}
+
+	userTags, err := parseFileUserTags(c.Tags, c.Files)
+	if err != nil {
+		return err
+	}
+
+	for i := range userTags {
+		if isVideo[i] {
+			return usage(fmt.Sprintf("user tags are only supported on images: %s", c.Files[i]))
+		}
+	}
+
+	productTags, err := parseFileProductTags(c.ProductTags, c.Files)
+	if err != nil {
+		return err
+	}
+
+	for i := range productTags {
+		if isVideo[i] {
+			return usage(fmt.Sprintf("product tags are only supported on images: %s", c.Files[i]))
+		}
+	}
+
+	fitCarouselTags(images, items, userTags, productTags)
+
+	altTexts := make(map[int]string, len(c.AltTexts))
+	for _, entry := range c.AltTexts {
+		idx, text, mapErr := splitFileMapping(entry, c.Files)
+		if mapErr != nil {
+			return mapErr
+		}
+
+		if isVideo[idx] {
+			return usage(fmt.Sprintf("alt text is only supported on images: %s", c.Files[idx]))
+		}
+
+		altTexts[idx] = text
+	}
+
+	collaborators, err := parseCollaborators(c.Collaborators)
+	if err != nil {
+		return err
+	}
+
 	if root != nil && root.UserID != "" {
 		cfg.IGUserID = root.UserID
 	}
@@ -35,30 +131,71 @@ func (c *CarouselCmd) Run(root *RootFlags) error {
 		return err
 	}
 
-	ctx := context.Background()
+	uploader, err := newUploader(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	defer releaseUploader(uploader)
+
+	resumable, err := resumableVideos(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	// Check remote items before uploading anything.
+	for _, item := range items {
+		if item.remote {
+			err = verifyMediaURL(ctx, &c.UploadFlags, item.source, item.media.IsVideo(), item.probed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	client := graph.NewClient(cfg)
-	childIDs := make([]string, 0, len(c.Files))
+	childIDs := make([]string, 0, len(items))
+	uploads := make([]upload.Hosted, 0, len(items))
 
-	for _, file := range c.Files {
-		var isVideo bool
-		isVideo, err = detectMediaType(file)
-		if err != nil {
-			return err
+	for i, item := range items {
+		mediaURL := item.source
+		uploadDirect := ""
+		switch {
+		case item.remote:
+		case isVideo[i] && resumable:
+			mediaURL = ""
+			uploadDirect = item.source
+		default:
+			var hosted upload.Hosted
+			hosted, err = uploadFile(ctx, uploader, item.path, item.source)
+			if err != nil {
+				return err
+			}
+			mediaURL = hosted.URL
+			uploads = append(uploads, hosted)
+
+			err = verifyMediaURL(ctx, &c.UploadFlags, mediaURL, isVideo[i], nil)
+			if err != nil {
+				return err
+			}
 		}
 
-		var mediaURL string
-		mediaURL, err = upload.Upload(ctx, file)
-		if err != nil {
-			return err
+		childOpts := graph.MediaOptions{
+			UserTags:    userTags[i],
+			ProductTags: productTags[i],
+			AltText:     altTexts[i],
+			UploadFile:  uploadDirect,
 		}
 
+		uploadCtx, endProgress := withProgress(ctx, uploadDirect)
 		var childID string
-		childID, err = client.CreateCarouselChild(ctx, mediaURL, isVideo)
+		childID, err = client.CreateCarouselChild(uploadCtx, mediaURL, isVideo[i], childOpts)
+		endProgress()
 		if err != nil {
 			return err
 		}
 
-		if isVideo {
+		if isVideo[i] {
 			err = client.PollStatus(ctx, childID, cfg.PollInterval, cfg.PollTimeout)
 			if err != nil {
 				return err
@@ -68,7 +205,12 @@ func (c *CarouselCmd) Run(root *RootFlags) error {
 		childIDs = append(childIDs, childID)
 	}
 
-	creationID, err := client.CreateCarouselContainer(ctx, childIDs, c.Caption)
+	opts := graph.MediaOptions{
+		LocationID:    c.LocationID,
+		Collaborators: collaborators,
+	}
+
+	creationID, err := client.CreateCarouselContainer(ctx, childIDs, c.Caption, opts)
 	if err != nil {
 		return err
 	}
@@ -78,6 +220,8 @@ func (c *CarouselCmd) Run(root *RootFlags) error {
 		return err
 	}
 
+	releaseUploader(uploader)
+
 	publishedID, err := client.Publish(ctx, creationID)
 	if err != nil {
 		return err
@@ -85,5 +229,80 @@ func (c *CarouselCmd) Run(root *RootFlags) error {
 
 	_, _ = fmt.Fprintf(os.Stdout, "CHILD_IDS=%s\n", strings.Join(childIDs, ","))
 	_, _ = fmt.Fprintf(os.Stdout, "PUBLISHED_MEDIA_ID=%s\n", publishedID)
-	return nil
+	cleanupHosted(ctx, &c.UploadFlags, uploader, uploads)
+
+	return postFirstComment(ctx, client, publishedID, firstComment)
+}
+
+// fitCarouselTags moves the tags on each local image with its crop or pad.
+// The pipeline knows images by their expanded path, not the entry as given.
+func fitCarouselTags(images *imagePipeline, items []carouselItem, userTags map[int][]graph.UserTag, productTags map[int][]graph.ProductTag) {
+	for i, item := range items {
+		if item.path != "" {
+			images.fitTags(item.path, userTags[i], productTags[i])
+		}
+	}
+}
+
+// resolveCarouselItems classifies each entry as a local file or remote URL,
+// detects its media type and prepares local images, so bad input fails
+// before anything is uploaded. Local files are checked before remote URLs
+// are probed.
+func resolveCarouselItems(ctx context.Context, images *imagePipeline, entries []string) ([]carouselItem, error) {
+	items := make([]carouselItem, len(entries))
+	imageIdx := make([]int, 0, len(entries))
+	imagePaths := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		if isRemoteURL(entry) {
+			mediaURL, err := ensureHTTPS(entry)
+			if err != nil {
+				return nil, usage(fmt.Sprintf("%s: %v", entry, err))
+			}
+
+			items[i] = carouselItem{source: mediaURL, remote: true}
+			continue
+		}
+
+		path := kong.ExpandPath(entry)
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, usage(fmt.Sprintf("%s: file not found", entry))
+		}
+		if stat.IsDir() {
+			return nil, usage(fmt.Sprintf("%s: is a directory", entry))
+		}
+
+		info, err := detectMedia(path)
+		if err != nil {
+			return nil, err
+		}
+
+		items[i] = carouselItem{source: path, path: path, media: info}
+		if !info.IsVideo() {
+			imageIdx = append(imageIdx, i)
+			imagePaths = append(imagePaths, path)
+		}
+	}
+
+	prepared, err := images.prepare(media.TargetCarousel, imagePaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	for n, i := range imageIdx {
+		items[i].source = prepared[n]
+	}
+
+	for i, item := range items {
+		if !item.remote {
+			continue
+		}
+
+		items[i].media, items[i].probed, err = probeURLMedia(ctx, item.source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"image"
+	"image/jpeg"
+	"math"
+	"os"
+	"testing"
+)
+
+func writeJPEG(t *testing.T, path string, width, height int) {
+	t.Helper()
+
+	f, err := os.Create(path) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err = jpeg.Encode(f, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+}
+
+func TestCarouselTagsFollowFitWithRelativePaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeJPEG(t, "wide.jpg", 3000, 1000)
+	writeJPEG(t, "tall.jpg", 1000, 2000)
+
+	files := []string{"wide.jpg", "tall.jpg"}
+
+	for _, tc := range []struct {
+		fit         string
+		userX       float64
+		productX    float64
+		description string
+	}{
+		// The wide image is cropped to 1910x1000 around x 545-2455; the tall
+		// one is already in range.
+		{fit: "crop", userX: (750 - 545) / 1910.0, productX: 0, description: "crop"},
+		// The wide image is padded to 3000x1571; the tall one to 1600x2000,
+		// with the image at x 300-1300.
+		{fit: "pad", userX: 0.25, productX: 300 / 1600.0, description: "pad"},
+	} {
+		images, err := newImagePipeline(nil, &ImageFlags{ConvertFlags: ConvertFlags{Convert: true}}, &FitFlags{Fit: tc.fit, PadBackground: "#000000"})
+		if err != nil {
+			t.Fatalf("%s: new pipeline: %v", tc.description, err)
+		}
+
+		items, err := resolveCarouselItems(context.Background(), images, files)
+		if err != nil {
+			t.Fatalf("%s: resolve items: %v", tc.description, err)
+		}
+
+		userTags, err := parseFileUserTags([]string{"wide.jpg=@partner:0.25,0.5"}, files)
+		if err != nil {
+			t.Fatalf("parse user tags: %v", err)
+		}
+
+		productTags, err := parseFileProductTags([]string{"tall.jpg=1234567890123456789:0,0.5"}, files)
+		if err != nil {
+			t.Fatalf("parse product tags: %v", err)
+		}
+
+		fitCarouselTags(images, items, userTags, productTags)
+		images.release()
+
+		if got := userTags[0][0].X; math.Abs(got-tc.userX) > 1e-3 {
+			t.Fatalf("%s: expected user tag at x=%.4f, got %.4f", tc.description, tc.userX, got)
+		}
+
+		if got := productTags[1][0].X; math.Abs(got-tc.productX) > 1e-3 {
+			t.Fatalf("%s: expected product tag at x=%.4f, got %.4f", tc.description, tc.productX, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/graph"
+)
+
+type CatalogCmd struct {
+	Search CatalogSearchCmd `cmd:"" help:"Search catalog products to use with --product-tag"`
+}
+
+type CatalogSearchCmd struct {
+	CatalogID string `help:"Product catalog ID" required:""`
+	Query     string `help:"Product name or retailer ID to search for"`
+}
+
+func (c *CatalogSearchCmd) Run(root *RootFlags) error {
+	if c.CatalogID == "" {
+		return usage("provide --catalog-id")
+	}
+
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	if root != nil && root.UserID != "" {
+		cfg.IGUserID = root.UserID
+	}
+
+	err = cfg.ValidateForAccessToken()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := graph.NewClient(cfg)
+	products, err := client.SearchCatalogProducts(ctx, c.CatalogID, c.Query)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		_, _ = fmt.Fprintf(os.Stdout, "PRODUCT_ID=%s\n", product.ProductID)
+		_, _ = fmt.Fprintf(os.Stdout, "PRODUCT_NAME=%s\n", product.Name)
+		_, _ = fmt.Fprintf(os.Stdout, "RETAILER_ID=%s\n", product.RetailerID)
+		_, _ = fmt.Fprintf(os.Stdout, "REVIEW_STATUS=%s\n", product.ReviewStatus)
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	if len(products) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "NO_PRODUCTS_FOUND")
+	}
+
+	return nil
+}
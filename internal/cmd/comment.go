@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mahmoudashraf93/poster/internal/graph"
+)
+
+// maxCommentLength is Instagram's limit on comment length, in characters.
+const maxCommentLength = 2200
+
+type FirstCommentFlags struct {
+	FirstComment     string `help:"Comment to post on the media right after publishing"`
+	FirstCommentFile string `help:"File containing the first comment" type:"existingfile"`
+}
+
+// resolve returns the first comment text, or "" when none was requested.
+func (f *FirstCommentFlags) resolve() (string, error) {
+	if f.FirstComment != "" && f.FirstCommentFile != "" {
+		return "", usage("provide only one of --first-comment or --first-comment-file")
+	}
+
+	text := f.FirstComment
+	if f.FirstCommentFile != "" {
+		// #nosec G304 -- path is user-provided
+		b, err := os.ReadFile(f.FirstCommentFile)
+		if err != nil {
+			return "", fmt.Errorf("read first comment: %w", err)
+		}
+
+		text = strings.TrimSpace(string(b))
+		if text == "" {
+			return "", usage("--first-comment-file is empty")
+		}
+	}
+
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return "", usage(fmt.Sprintf("first comment is too long (max %d characters)", maxCommentLength))
+	}
+
+	return text, nil
+}
+
+// postFirstComment comments on freshly published media. The post is already
+// live at this point, so a failure is reported as a partial success.
+func postFirstComment(ctx context.Context, client *graph.Client, mediaID, text string) error {
+	if text == "" {
+		return nil
+	}
+
+	commentID, err := client.CreateComment(ctx, mediaID, text)
+	if err != nil {
+		return partialSuccess(fmt.Errorf("published %s, but posting the first comment failed: %w", mediaID, err))
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "FIRST_COMMENT_ID=%s\n", commentID)
+	return nil
+}
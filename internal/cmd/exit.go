@@ -2,6 +2,15 @@ package cmd
 
 import "errors"
 
+const (
+	// exitPartialSuccess is returned when media was published but a
+	// follow-up step (such as the first comment) failed.
+	exitPartialSuccess = 3
+	// exitInvalidMedia is returned when local media fails the image checks,
+	// so scripts can tell it apart from usage errors (2).
+	exitInvalidMedia = 4
+)
+
 type ExitError struct {
 	Code int
 	Err  error
@@ -38,3 +47,11 @@ func ExitCode(err error) int {
 func usage(msg string) error {
 	return &ExitError{Code: 2, Err: errors.New(msg)}
 }
+
+func partialSuccess(err error) error {
+	return &ExitError{Code: exitPartialSuccess, Err: err}
+}
+
+func invalidMedia(err error) error {
+	return &ExitError{Code: exitInvalidMedia, Err: err}
+}
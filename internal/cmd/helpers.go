@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"path/filepath"
 	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/media"
+	"github.com/mahmoudashraf93/poster/internal/upload"
 )
 
 func ensureHTTPS(raw string) (string, error) {
@@ -21,14 +26,129 @@ func ensureHTTPS(raw string) (string, error) {
 	return raw, nil
 }
 
-func detectMediaType(path string) (isVideo bool, err error) {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
-		return false, nil
-	case ".mp4", ".mov":
-		return true, nil
-	default:
-		return false, fmt.Errorf("unsupported file extension: %s", ext)
+// detectMedia identifies a local file from its content, so a renamed or
+// extension-less file is still routed correctly, and rejects formats
+// Instagram does not accept.
+func detectMedia(path string) (media.Info, error) {
+	info, err := media.DetectFile(path)
+	if err != nil {
+		return media.Info{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !info.Supported() {
+		return media.Info{}, fmt.Errorf("%s: %s files are not supported (use JPEG, PNG, GIF, WebP, MP4 or MOV)", path, strings.ToUpper(info.Container))
+	}
+
+	if ext, extErr := media.FromExtension(path); extErr == nil && ext.Container != info.Container {
+		slog.Debug("file extension does not match content", "file", path, "extension", ext.Container, "content", info.Container)
+	}
+
+	return info, nil
+}
+
+// detectURLMedia guesses the media type of a remote URL from its path.
+func detectURLMedia(raw string) (media.Info, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return media.Info{}, fmt.Errorf("invalid url: %w", err)
+	}
+
+	return media.FromExtension(parsed.Path)
+}
+
+// probeURLMedia detects the media type of a remote URL from its path,
+// falling back to the Content-Type the URL reports. When it had to ask the
+// URL, the probe is returned so verifyMediaURL does not fetch it again.
+func probeURLMedia(ctx context.Context, raw string) (media.Info, *upload.URLInfo, error) {
+	info, err := detectURLMedia(raw)
+	if err == nil {
+		return info, nil, nil
 	}
+
+	probed, err := upload.NewVerifier().Probe(ctx, raw)
+	if err != nil {
+		return media.Info{}, nil, fmt.Errorf("probe %s: %w", redactURL(raw), err)
+	}
+
+	info, err = media.FromContentType(probed.ContentType)
+	if err != nil {
+		return media.Info{}, nil, fmt.Errorf("cannot tell image from video for %s (content type %q)", redactURL(raw), probed.ContentType)
+	}
+
+	return info, &probed, nil
+}
+
+func isRemoteURL(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+// splitFileMapping splits a "file=value" flag entry and resolves file to its
+// index in files. The file may be given as listed or by its base name; the
+// longest match wins so values may themselves contain '='.
+func splitFileMapping(raw string, files []string) (int, string, error) {
+	best := -1
+	bestLen := 0
+	ambiguous := false
+
+	for i, file := range files {
+		for _, key := range []string{file, filepath.Base(file)} {
+			if !strings.HasPrefix(raw, key+"=") {
+				continue
+			}
+
+			switch {
+			case len(key) > bestLen:
+				best, bestLen, ambiguous = i, len(key), false
+			case len(key) == bestLen && best != i:
+				ambiguous = true
+			}
+		}
+	}
+
+	if best < 0 {
+		return 0, "", usage(fmt.Sprintf("invalid mapping %q (expected <file>=<value> for a listed file)", raw))
+	}
+
+	if ambiguous {
+		return 0, "", usage(fmt.Sprintf("ambiguous mapping %q (matches more than one file)", raw))
+	}
+
+	return best, raw[bestLen+1:], nil
+}
+
+// verifyMediaURL checks that Meta will be able to fetch mediaURL, unless
+// --no-verify-urls was given. probed is an earlier probe of the URL, if any,
+// which is checked instead of fetching the URL again.
+func verifyMediaURL(ctx context.Context, flags *UploadFlags, mediaURL string, isVideo bool, probed *upload.URLInfo) error {
+	if flags != nil && !flags.VerifyURLs {
+		return nil
+	}
+
+	verifier := upload.NewVerifier()
+
+	var err error
+	if probed != nil {
+		err = verifier.Check(*probed, isVideo)
+	} else {
+		_, err = verifier.Verify(ctx, mediaURL, isVideo)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", redactURL(mediaURL), err)
+	}
+
+	return nil
+}
+
+// redactURL drops the query string, which may hold signatures or tokens.
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
 }
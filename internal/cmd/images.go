@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/graph"
+	"github.com/mahmoudashraf93/poster/internal/media"
+)
+
+// FitFlags choose how local images outside the target's aspect ratio range
+// are fixed before uploading.
+type FitFlags struct {
+	Fit           string `help:"Fix images outside the allowed aspect ratio: crop|pad|none" default:"none"`
+	Focus         string `help:"Point to keep when cropping, as x,y in 0-1 (default: center)" placeholder:"X,Y"`
+	PadBackground string `help:"What padding is filled with: a #rrggbb color or blur (a blurred copy of the image)" default:"blur"`
+}
+
+// options turns the flags into fit options; the aspect ratio range is set
+// per target later.
+func (f *FitFlags) options() (media.FitOptions, error) {
+	opts := media.FitOptions{Mode: media.FitNone, FocusX: 0.5, FocusY: 0.5}
+	if f == nil {
+		return opts, nil
+	}
+
+	switch mode := media.FitMode(strings.ToLower(strings.TrimSpace(f.Fit))); mode {
+	case "", media.FitNone:
+	case media.FitCrop, media.FitPad:
+		opts.Mode = mode
+	default:
+		return opts, usage(fmt.Sprintf("invalid --fit %q (expected crop, pad or none)", f.Fit))
+	}
+
+	if f.Focus != "" {
+		x, y, err := parsePosition(f.Focus)
+		if err != nil {
+			return opts, usage(fmt.Sprintf("invalid --focus %q: %v", f.Focus, err))
+		}
+
+		opts.FocusX, opts.FocusY = x, y
+	}
+
+	if background := strings.TrimSpace(f.PadBackground); background != "" && !strings.EqualFold(background, "blur") {
+		padColor, err := media.ParseColor(background)
+		if err != nil {
+			return opts, usage(fmt.Sprintf("--pad-background: %v", err))
+		}
+
+		opts.PadColor = padColor
+	}
+
+	return opts, nil
+}
+
+// ConvertFlags control how local images Instagram does not take as they are
+// are converted to JPEG.
+type ConvertFlags struct {
+	Convert           bool   `help:"Convert PNG, WebP and GIF images (and JPEGs over 8 MB) to JPEG before uploading" default:"true" negatable:""`
+	ConvertBackground string `help:"Color transparent areas are flattened onto when converting, as #rrggbb" default:"#ffffff"`
+}
+
+// ImageFlags control how local images are prepared and checked before they
+// are uploaded. They are embedded in the publishing commands.
+type ImageFlags struct {
+	Validate bool `help:"Check local images against Instagram's rules before uploading" default:"true" negatable:""`
+
+	ConvertFlags `embed:""`
+}
+
+// imagePipeline gets local images ready before anything is uploaded: it
+// applies the EXIF orientation and strips metadata such as GPS coordinates,
+// crops or pads them as --fit asks, converts images Instagram does not take
+// as they are to JPEG (unless --no-convert) and checks the result against
+// the target's rules (unless --no-validate). Rewritten files live in a temp
+// dir removed by release.
+type imagePipeline struct {
+	convert  bool
+	validate bool
+	fit      media.FitOptions
+	opts     media.ConvertOptions
+	dir      string
+	// fitted holds how each cropped or padded file was fitted, by the path
+	// the user gave, so tag positions can follow the image.
+	fitted map[string]fittedImage
+}
+
+// fittedImage is the fit applied to an upright image of width x height.
+type fittedImage struct {
+	fit           media.FitOptions
+	width, height int
+}
+
+// newImagePipeline builds the pipeline from the image flags and the
+// configured metadata setting. fit may be nil for images that are never
+// fitted.
+func newImagePipeline(cfg *config.Config, flags *ImageFlags, fit *FitFlags) (*imagePipeline, error) {
+	fitOpts, err := fit.options()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &imagePipeline{convert: true, validate: true, fit: fitOpts}
+	if flags != nil {
+		p.convert = flags.Convert
+		p.validate = flags.Validate
+
+		if flags.ConvertBackground != "" {
+			background, err := media.ParseColor(flags.ConvertBackground)
+			if err != nil {
+				return nil, usage(fmt.Sprintf("--convert-background: %v", err))
+			}
+
+			p.opts.Background = background
+		}
+	}
+
+	if cfg != nil {
+		p.opts.KeepCopyright = cfg.KeepCopyright
+	}
+
+	return p, nil
+}
+
+// prepare returns the files to upload in place of paths, in the same order.
+// An empty target skips fitting and validation. Every problem with every file is
+// reported together, with the invalid media exit code.
+func (p *imagePipeline) prepare(target media.Target, paths ...string) ([]string, error) {
+	prepared := make([]string, len(paths))
+	var errs []error
+	for i, path := range paths {
+		out, _, err := p.convertFile(path, target)
+		if err != nil {
+			var pathErr *os.PathError
+			if errors.As(err, &pathErr) {
+				return nil, err
+			}
+
+			errs = append(errs, err)
+			continue
+		}
+
+		prepared[i] = out
+
+		if err = p.check(path, out, target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, invalidMedia(errors.Join(errs...))
+	}
+
+	return prepared, nil
+}
+
+// convertFile returns path, or a rewritten JPEG copy when the image has to be
+// turned upright, fitted to target or converted, or carries metadata. It
+// reports whether the image was re-encoded rather than only stripped.
+func (p *imagePipeline) convertFile(path string, target media.Target) (string, bool, error) {
+	fit := p.fit
+	fit.MinAspect, fit.MaxAspect = media.FitRange(target)
+
+	img, err := media.DecodeImage(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	meta, err := media.ReadMetadata(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	width, height := meta.Size(img.Width, img.Height)
+	needsFit := fit.Needed(width, height)
+	reencode := needsFit || meta.Rotated() || p.convert && media.NeedsConversion(img)
+	if !reencode && !meta.Present {
+		return path, false, nil
+	}
+
+	if p.dir == "" {
+		p.dir, err = os.MkdirTemp("", "poster-convert-")
+		if err != nil {
+			return "", false, fmt.Errorf("create conversion dir: %w", err)
+		}
+
+		p.opts.Dir = p.dir
+	}
+
+	if !reencode {
+		out, err := media.StripMetadata(path, p.opts)
+		if err != nil {
+			return "", false, err
+		}
+
+		slog.Debug("stripped image metadata", "file", path, "to", out)
+		return out, false, nil
+	}
+
+	opts := p.opts
+	opts.Fit = fit
+
+	out, err := media.ToJPEG(path, opts)
+	if err != nil {
+		return "", false, err
+	}
+
+	if needsFit {
+		if p.fitted == nil {
+			p.fitted = make(map[string]fittedImage)
+		}
+
+		p.fitted[path] = fittedImage{fit: fit, width: width, height: height}
+	}
+
+	slog.Debug("converted image to jpeg", "file", path, "from", img.Container, "orientation", meta.Orientation, "fit", needsFit, "to", out)
+	return out, true, nil
+}
+
+// fitTags moves tag positions on path, given against the image as the user
+// sees it, to where they land once it is cropped or padded. Tags a crop cuts
+// off are moved to the nearest edge.
+func (p *imagePipeline) fitTags(path string, users []graph.UserTag, products []graph.ProductTag) {
+	fitted, ok := p.fitted[path]
+	if !ok {
+		return
+	}
+
+	move := func(what string, x, y *float64) {
+		var inside bool
+		*x, *y, inside = fitted.fit.MapPoint(fitted.width, fitted.height, *x, *y)
+		if !inside {
+			slog.Warn("tag moved inside the cropped image", "file", path, "tag", what)
+		}
+	}
+
+	for i := range users {
+		move("@"+users[i].Username, &users[i].X, &users[i].Y)
+	}
+
+	for i := range products {
+		move(products[i].ProductID, &products[i].X, &products[i].Y)
+	}
+}
+
+// check validates out, the file that will be uploaded, but reports problems
+// under path, the file the user gave.
+func (p *imagePipeline) check(path, out string, target media.Target) error {
+	if !p.validate || target == "" {
+		return nil
+	}
+
+	_, violations, err := media.ValidateImage(out, target)
+	for _, v := range violations {
+		if v.Warning {
+			slog.Warn("image will be adjusted by instagram", "file", path, "rule", v.Rule, "detail", v.Message)
+		}
+	}
+
+	var validationErr *media.ValidationError
+	if errors.As(err, &validationErr) {
+		validationErr.Path = path
+	}
+
+	return err
+}
+
+// release removes converted files. Call it once the uploader is done with
+// them.
+func (p *imagePipeline) release() {
+	if p.dir == "" {
+		return
+	}
+
+	if err := os.RemoveAll(p.dir); err != nil {
+		slog.Warn("remove converted images", "dir", p.dir, "err", err)
+	}
+}
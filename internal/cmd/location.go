@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/graph"
+)
+
+type LocationCmd struct {
+	Search LocationSearchCmd `cmd:"" help:"Search places to use with --location-id"`
+}
+
+type LocationSearchCmd struct {
+	Query string   `help:"Place name to search for" required:""`
+	Lat   *float64 `help:"Latitude to sort the returned results by distance (requires --lng); not sent with the search"`
+	Lng   *float64 `help:"Longitude to sort the returned results by distance (requires --lat); not sent with the search"`
+}
+
+func (c *LocationSearchCmd) Run(root *RootFlags) error {
+	if c.Query == "" {
+		return usage("provide --query")
+	}
+	if (c.Lat == nil) != (c.Lng == nil) {
+		return usage("provide both --lat and --lng")
+	}
+	if c.Lat != nil && (*c.Lat < -90 || *c.Lat > 90 || *c.Lng < -180 || *c.Lng > 180) {
+		return usage("--lat must be within -90..90 and --lng within -180..180")
+	}
+
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.ValidateForGraphLookup()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := graph.NewClient(cfg)
+	locations, err := client.SearchLocations(ctx, c.Query)
+	if err != nil {
+		return err
+	}
+
+	var distances []float64
+	if c.Lat != nil {
+		sort.SliceStable(locations, func(i, j int) bool {
+			return distanceMeters(*c.Lat, *c.Lng, locations[i]) < distanceMeters(*c.Lat, *c.Lng, locations[j])
+		})
+
+		distances = make([]float64, len(locations))
+		for i, location := range locations {
+			distances[i] = distanceMeters(*c.Lat, *c.Lng, location)
+		}
+	}
+
+	for i, location := range locations {
+		_, _ = fmt.Fprintf(os.Stdout, "LOCATION_ID=%s\n", location.ID)
+		_, _ = fmt.Fprintf(os.Stdout, "LOCATION_NAME=%s\n", location.Name)
+		_, _ = fmt.Fprintf(os.Stdout, "LOCATION_ADDRESS=%s\n", location.Address())
+		if distances != nil {
+			_, _ = fmt.Fprintf(os.Stdout, "DISTANCE_M=%.0f\n", distances[i])
+		}
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	if len(locations) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "NO_LOCATIONS_FOUND")
+	}
+
+	return nil
+}
+
+// distanceMeters returns the great-circle distance between a point and a location.
+func distanceMeters(lat, lng float64, location graph.Location) float64 {
+	const earthRadius = 6371000.0
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(location.Latitude - lat)
+	dLng := toRad(location.Longitude - lng)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat))*math.Cos(toRad(location.Latitude))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
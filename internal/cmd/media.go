@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/graph"
+)
+
+type MediaCmd struct {
+	Collaborators MediaCollaboratorsCmd `cmd:"" help:"Show collaborator invites and their status"`
+	ProductTags   MediaProductTagsCmd   `cmd:"" name:"product-tags" help:"List or update product tags"`
+	Trial         MediaTrialCmd         `cmd:"" help:"Show trial reel status"`
+}
+
+type MediaCollaboratorsCmd struct {
+	MediaID string `arg:"" name:"media-id" help:"Published media ID"`
+}
+
+func (c *MediaCollaboratorsCmd) Run(root *RootFlags) error {
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.ValidateForGraphLookup()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := graph.NewClient(cfg)
+	collaborators, err := client.FetchCollaborators(ctx, c.MediaID)
+	if err != nil {
+		return err
+	}
+
+	for _, collaborator := range collaborators {
+		_, _ = fmt.Fprintf(os.Stdout, "COLLABORATOR_ID=%s\n", collaborator.ID)
+		_, _ = fmt.Fprintf(os.Stdout, "COLLABORATOR_USERNAME=%s\n", collaborator.Username)
+		_, _ = fmt.Fprintf(os.Stdout, "INVITE_STATUS=%s\n", collaborator.InviteStatus)
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	if len(collaborators) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "NO_COLLABORATORS_FOUND")
+	}
+
+	return nil
+}
+
+type MediaProductTagsCmd struct {
+	MediaID string   `arg:"" name:"media-id" help:"Published media ID"`
+	Set     []string `sep:"none" help:"Add or move a product tag: <product_id>:x,y with x,y in 0-1 (repeatable)"`
+	Remove  []string `help:"Remove the tag for a product ID (repeatable)"`
+}
+
+func (c *MediaProductTagsCmd) Run(root *RootFlags) error {
+	updated, err := parseProductTags(c.Set)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range c.Remove {
+		if !validProductID(id) {
+			return usage(fmt.Sprintf("invalid product id %q", id))
+		}
+	}
+
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.ValidateForGraphLookup()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := graph.NewClient(cfg)
+
+	if len(c.Remove) > 0 {
+		err = client.DeleteProductTags(ctx, c.MediaID, c.Remove)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(updated) > 0 {
+		err = client.UpdateProductTags(ctx, c.MediaID, updated)
+		if err != nil {
+			return err
+		}
+	}
+
+	tags, err := client.FetchProductTags(ctx, c.MediaID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, _ = fmt.Fprintf(os.Stdout, "PRODUCT_ID=%s\n", tag.ProductID)
+		_, _ = fmt.Fprintf(os.Stdout, "PRODUCT_NAME=%s\n", tag.Name)
+		_, _ = fmt.Fprintf(os.Stdout, "POSITION=%g,%g\n", tag.X, tag.Y)
+		_, _ = fmt.Fprintf(os.Stdout, "REVIEW_STATUS=%s\n", tag.ReviewStatus)
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	if len(tags) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "NO_PRODUCT_TAGS_FOUND")
+	}
+
+	return nil
+}
+
+type MediaTrialCmd struct {
+	MediaID string `arg:"" name:"media-id" help:"Published trial reel ID"`
+}
+
+func (c *MediaTrialCmd) Run(root *RootFlags) error {
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.ValidateForGraphLookup()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := graph.NewClient(cfg)
+
+	status, err := client.FetchTrialStatus(ctx, c.MediaID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "MEDIA_PRODUCT_TYPE=%s\n", status.MediaProductType)
+	_, _ = fmt.Fprintf(os.Stdout, "IS_TRIAL=%t\n", status.IsTrial)
+	_, _ = fmt.Fprintf(os.Stdout, "GRADUATION_STRATEGY=%s\n", strings.ToLower(status.GraduationStrategy))
+	return nil
+}
@@ -7,13 +7,24 @@ import (
 
 	"github.com/mahmoudashraf93/poster/internal/config"
 	"github.com/mahmoudashraf93/poster/internal/graph"
+	"github.com/mahmoudashraf93/poster/internal/media"
 	"github.com/mahmoudashraf93/poster/internal/upload"
 )
 
 type PhotoCmd struct {
-	File    string `help:"Local image file" type:"existingfile"`
-	URL     string `help:"Public HTTPS image URL (skip upload)"`
-	Caption string `help:"Post caption" short:"c"`
+	File          string   `help:"Local image file" type:"existingfile"`
+	URL           string   `help:"Public HTTPS image URL (skip upload)"`
+	Caption       string   `help:"Post caption" short:"c"`
+	Tags          []string `name:"tag" sep:"none" help:"Tag a user at a position: @username:x,y with x,y in 0-1 (repeatable)"`
+	LocationID    string   `help:"Facebook Place ID to tag (see: poster location search)"`
+	ProductTags   []string `name:"product-tag" sep:"none" help:"Tag a catalog product: <product_id>:x,y with x,y in 0-1 (repeatable)"`
+	AltText       string   `help:"Alt text describing the image for screen readers"`
+	Collaborators []string `name:"collaborator" help:"Invite a collaborator by username (repeatable, max 3)"`
+
+	FirstCommentFlags `embed:""`
+	FitFlags          `embed:""`
+	ImageFlags        `embed:""`
+	UploadFlags       `embed:""`
 }
 
 func (c *PhotoCmd) Run(root *RootFlags) error {
@@ -24,11 +35,56 @@ func (c *PhotoCmd) Run(root *RootFlags) error {
 		return usage("provide only one of --file or --url")
 	}
 
+	firstComment, err := c.FirstCommentFlags.resolve()
+	if err != nil {
+		return err
+	}
+
+	err = checkPhotoMedia(c.File, c.URL, c.AltText)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.LoadWithProfile(root.Profile)
 	if err != nil {
 		return err
 	}
 
+	images, err := newImagePipeline(cfg, &c.ImageFlags, &c.FitFlags)
+	if err != nil {
+		return err
+	}
+
+	defer images.release()
+
+	file := c.File
+	if file != "" {
+		var prepared []string
+		prepared, err = images.prepare(media.TargetFeed, file)
+		if err != nil {
+			return err
+		}
+
+		file = prepared[0]
+	}
+
+	userTags, err := parseUserTags(c.Tags)
+	if err != nil {
+		return err
+	}
+
+	productTags, err := parseProductTags(c.ProductTags)
+	if err != nil {
+		return err
+	}
+
+	images.fitTags(c.File, userTags, productTags)
+
+	collaborators, err := parseCollaborators(c.Collaborators)
+	if err != nil {
+		return err
+	}
+
 	if root != nil && root.UserID != "" {
 		cfg.IGUserID = root.UserID
 	}
@@ -38,6 +94,15 @@ func (c *PhotoCmd) Run(root *RootFlags) error {
 		return err
 	}
 
+	uploader, err := newUploader(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	defer releaseUploader(uploader)
+
+	var uploads []upload.Hosted
+
 	ctx := context.Background()
 	mediaURL := c.URL
 	if mediaURL != "" {
@@ -46,23 +111,76 @@ func (c *PhotoCmd) Run(root *RootFlags) error {
 			return err
 		}
 	} else {
-		mediaURL, err = upload.Upload(ctx, c.File)
+		var hosted upload.Hosted
+		hosted, err = uploadFile(ctx, uploader, c.File, file)
 		if err != nil {
 			return err
 		}
+		mediaURL = hosted.URL
+		uploads = append(uploads, hosted)
+	}
+
+	err = verifyMediaURL(ctx, &c.UploadFlags, mediaURL, false, nil)
+	if err != nil {
+		return err
+	}
+
+	opts := graph.MediaOptions{
+		UserTags:      userTags,
+		LocationID:    c.LocationID,
+		Collaborators: collaborators,
+		ProductTags:   productTags,
+		AltText:       c.AltText,
 	}
 
 	client := graph.NewClient(cfg)
-	creationID, err := client.CreatePhotoContainer(ctx, mediaURL, c.Caption)
+	creationID, err := client.CreatePhotoContainer(ctx, mediaURL, c.Caption, opts)
 	if err != nil {
 		return err
 	}
 
+	if c.File != "" && servesLocally(uploader) {
+		err = client.PollStatus(ctx, creationID, cfg.PollInterval, cfg.PollTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	releaseUploader(uploader)
+
 	publishedID, err := client.Publish(ctx, creationID)
 	if err != nil {
 		return err
 	}
 
 	_, _ = fmt.Fprintf(os.Stdout, "PUBLISHED_MEDIA_ID=%s\n", publishedID)
+	cleanupHosted(ctx, &c.UploadFlags, uploader, uploads)
+
+	return postFirstComment(ctx, client, publishedID, firstComment)
+}
+
+// checkPhotoMedia rejects local files that are not images. Remote URLs can
+// only be guessed from their path, so they are only checked when alt text,
+// which Instagram accepts on images alone, is given.
+func checkPhotoMedia(file, rawURL, altText string) error {
+	if file != "" {
+		info, err := detectMedia(file)
+		if err != nil {
+			return err
+		}
+
+		if info.IsVideo() {
+			return usage("--file must be an image (use poster reel or poster story for videos)")
+		}
+
+		return nil
+	}
+
+	if altText != "" {
+		if info, err := detectURLMedia(rawURL); err == nil && info.IsVideo() {
+			return usage("--alt-text is only supported on images")
+		}
+	}
+
 	return nil
 }
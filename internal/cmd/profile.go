@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mahmoudashraf93/poster/internal/config"
 	"github.com/mahmoudashraf93/poster/internal/secrets"
@@ -22,6 +25,29 @@ type ProfileSetCmd struct {
 	UserID      *string `name:"profile-user-id" help:"Instagram user ID"`
 	PageID      *string `name:"profile-page-id" help:"Facebook Page ID"`
 	BusinessID  *string `name:"profile-business-id" help:"Business ID"`
+	Uploader    *string `name:"profile-uploader" help:"Media hosting backend for local files"`
+
+	UploadCommand *string `name:"profile-upload-command" help:"Executable run by the command uploader"`
+	VideoUpload   *string `name:"profile-video-upload" help:"How local videos reach Meta: hosted or resumable"`
+	KeepCopyright *bool   `name:"profile-keep-copyright" help:"Keep the EXIF artist and copyright when stripping image metadata"`
+
+	S3Endpoint        *string `name:"profile-s3-endpoint" help:"S3-compatible endpoint URL (empty for AWS)"`
+	S3Region          *string `name:"profile-s3-region" help:"S3 region"`
+	S3Bucket          *string `name:"profile-s3-bucket" help:"S3 bucket"`
+	S3Prefix          *string `name:"profile-s3-prefix" help:"S3 object key prefix"`
+	S3AccessKeyID     *string `name:"profile-s3-access-key-id" help:"S3 access key ID"`
+	S3URLExpiry       *string `name:"profile-s3-url-expiry" help:"Presigned URL lifetime (e.g. 1h)"`
+	S3SecretAccessKey *string `name:"s3-secret-access-key" help:"S3 secret access key (stored in keychain)"`
+
+	ServePublicBaseURL *string `name:"profile-serve-public-base-url" help:"Public HTTPS URL that reaches the serve uploader"`
+	ServeListen        *string `name:"profile-serve-listen" help:"Listen address for the serve uploader (default 127.0.0.1:8080)"`
+	ServeTTL           *string `name:"profile-serve-ttl" help:"Maximum time the serve uploader keeps a file available (e.g. 30m)"`
+
+	UploadAttempts      *int    `name:"profile-upload-attempts" help:"Upload attempts per host, including the first (0 for the default)"`
+	UploadBackoff       *string `name:"profile-upload-backoff" help:"Initial upload retry backoff, doubled per retry (e.g. 1s)"`
+	UploadMaxBackoff    *string `name:"profile-upload-max-backoff" help:"Maximum upload retry backoff (e.g. 30s)"`
+	UploadRetryStatuses *string `name:"profile-upload-retry-statuses" help:"Comma-separated HTTP statuses worth retrying (empty for the default)"`
+	UguuMirrors         *string `name:"profile-uguu-mirrors" help:"Comma-separated fallback pomf upload URLs, tried in order"`
 }
 
 func (c *ProfileSetCmd) Run(root *RootFlags) error {
@@ -67,6 +93,41 @@ func (c *ProfileSetCmd) Run(root *RootFlags) error {
 		profile.BusinessID = *c.BusinessID
 	}
 
+	if c.Uploader != nil {
+		if *c.Uploader == "" {
+			return usage("--profile-uploader cannot be empty")
+		}
+		profile.Uploader = *c.Uploader
+	}
+
+	if c.UploadCommand != nil {
+		profile.UploadCommand = *c.UploadCommand
+	}
+
+	if c.VideoUpload != nil {
+		mode := strings.ToLower(strings.TrimSpace(*c.VideoUpload))
+		if mode != "" && mode != videoUploadHosted && mode != videoUploadResumable {
+			return usage(fmt.Sprintf("invalid --profile-video-upload %q (expected %s or %s)", *c.VideoUpload, videoUploadHosted, videoUploadResumable))
+		}
+		profile.VideoUpload = mode
+	}
+
+	if c.KeepCopyright != nil {
+		profile.KeepCopyright = c.KeepCopyright
+	}
+
+	if err := c.applyS3(&profile); err != nil {
+		return err
+	}
+
+	if err := c.applyServe(&profile); err != nil {
+		return err
+	}
+
+	if err := c.applyRetry(&profile); err != nil {
+		return err
+	}
+
 	cfg.Profiles[name] = profile
 
 	if err := config.WriteProfiles(cfg); err != nil {
@@ -82,9 +143,165 @@ func (c *ProfileSetCmd) Run(root *RootFlags) error {
 		}
 	}
 
+	if c.S3SecretAccessKey != nil {
+		if *c.S3SecretAccessKey == "" {
+			return usage("--s3-secret-access-key cannot be empty")
+		}
+		if err := secrets.SetS3SecretAccessKey(name, *c.S3SecretAccessKey); err != nil {
+			return err
+		}
+	}
+
 	return printProfile(name, profile)
 }
 
+// applyS3 copies the S3 flags that were given onto the profile. Empty values
+// clear a field.
+func (c *ProfileSetCmd) applyS3(profile *config.Profile) error {
+	fields := []struct {
+		value *string
+		dst   func(*config.S3Profile) *string
+	}{
+		{c.S3Endpoint, func(p *config.S3Profile) *string { return &p.Endpoint }},
+		{c.S3Region, func(p *config.S3Profile) *string { return &p.Region }},
+		{c.S3Bucket, func(p *config.S3Profile) *string { return &p.Bucket }},
+		{c.S3Prefix, func(p *config.S3Profile) *string { return &p.Prefix }},
+		{c.S3AccessKeyID, func(p *config.S3Profile) *string { return &p.AccessKeyID }},
+		{c.S3URLExpiry, func(p *config.S3Profile) *string { return &p.URLExpiry }},
+	}
+
+	if c.S3URLExpiry != nil && *c.S3URLExpiry != "" {
+		if _, err := time.ParseDuration(*c.S3URLExpiry); err != nil {
+			return usage(fmt.Sprintf("invalid --profile-s3-url-expiry: %v", err))
+		}
+	}
+
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+
+		if profile.S3 == nil {
+			profile.S3 = &config.S3Profile{}
+		}
+
+		*field.dst(profile.S3) = *field.value
+	}
+
+	if profile.S3 != nil && *profile.S3 == (config.S3Profile{}) {
+		profile.S3 = nil
+	}
+
+	return nil
+}
+
+// applyServe copies the serve flags that were given onto the profile. Empty
+// values clear a field.
+func (c *ProfileSetCmd) applyServe(profile *config.Profile) error {
+	fields := []struct {
+		value *string
+		dst   func(*config.ServeProfile) *string
+	}{
+		{c.ServePublicBaseURL, func(p *config.ServeProfile) *string { return &p.PublicBaseURL }},
+		{c.ServeListen, func(p *config.ServeProfile) *string { return &p.Listen }},
+		{c.ServeTTL, func(p *config.ServeProfile) *string { return &p.TTL }},
+	}
+
+	if c.ServePublicBaseURL != nil && *c.ServePublicBaseURL != "" {
+		if _, err := ensureHTTPS(*c.ServePublicBaseURL); err != nil {
+			return usage(fmt.Sprintf("invalid --profile-serve-public-base-url: %v", err))
+		}
+	}
+
+	if c.ServeTTL != nil && *c.ServeTTL != "" {
+		if _, err := time.ParseDuration(*c.ServeTTL); err != nil {
+			return usage(fmt.Sprintf("invalid --profile-serve-ttl: %v", err))
+		}
+	}
+
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+
+		if profile.Serve == nil {
+			profile.Serve = &config.ServeProfile{}
+		}
+
+		*field.dst(profile.Serve) = *field.value
+	}
+
+	if profile.Serve != nil && *profile.Serve == (config.ServeProfile{}) {
+		profile.Serve = nil
+	}
+
+	return nil
+}
+
+// applyRetry copies the upload retry and mirror flags that were given onto
+// the profile. Empty values clear a field.
+func (c *ProfileSetCmd) applyRetry(profile *config.Profile) error {
+	retry := config.RetryProfile{}
+	if profile.Retry != nil {
+		retry = *profile.Retry
+	}
+
+	if c.UploadAttempts != nil {
+		if *c.UploadAttempts < 0 {
+			return usage("--profile-upload-attempts cannot be negative")
+		}
+		retry.Attempts = *c.UploadAttempts
+	}
+
+	for _, field := range []struct {
+		flag  string
+		value *string
+		dst   *string
+	}{
+		{"--profile-upload-backoff", c.UploadBackoff, &retry.BaseDelay},
+		{"--profile-upload-max-backoff", c.UploadMaxBackoff, &retry.MaxDelay},
+	} {
+		if field.value == nil {
+			continue
+		}
+
+		if *field.value != "" {
+			if _, err := time.ParseDuration(*field.value); err != nil {
+				return usage(fmt.Sprintf("invalid %s: %v", field.flag, err))
+			}
+		}
+		*field.dst = *field.value
+	}
+
+	if c.UploadRetryStatuses != nil {
+		retry.Statuses = nil
+		for _, entry := range config.SplitList(*c.UploadRetryStatuses) {
+			status, err := strconv.Atoi(entry)
+			if err != nil || status < 100 || status > 599 {
+				return usage(fmt.Sprintf("invalid --profile-upload-retry-statuses entry %q", entry))
+			}
+			retry.Statuses = append(retry.Statuses, status)
+		}
+	}
+
+	profile.Retry = &retry
+	if retry.Attempts == 0 && retry.BaseDelay == "" && retry.MaxDelay == "" && len(retry.Statuses) == 0 {
+		profile.Retry = nil
+	}
+
+	if c.UguuMirrors != nil {
+		mirrors := config.SplitList(*c.UguuMirrors)
+		for _, mirror := range mirrors {
+			if _, err := ensureHTTPS(mirror); err != nil {
+				return usage(fmt.Sprintf("invalid --profile-uguu-mirrors entry %q: %v", mirror, err))
+			}
+		}
+		profile.UguuMirrors = mirrors
+	}
+
+	return nil
+}
+
 type ProfileShowCmd struct {
 	Name string `arg:"" optional:"" help:"Profile name (defaults to current)"`
 }
@@ -170,6 +387,11 @@ func (c *ProfileDeleteCmd) Run() error {
 		return err
 	}
 
+	_, err = secrets.DeleteS3SecretAccessKey(name)
+	if err != nil {
+		return err
+	}
+
 	_, _ = fmt.Fprintf(os.Stdout, "DELETED_PROFILE=%s\n", name)
 	return nil
 }
@@ -179,6 +401,14 @@ func printProfile(name string, profile config.Profile) error {
 	_, _ = fmt.Fprintf(os.Stdout, "IG_USER_ID=%s\n", profile.IGUserID)
 	_, _ = fmt.Fprintf(os.Stdout, "PAGE_ID=%s\n", profile.PageID)
 	_, _ = fmt.Fprintf(os.Stdout, "BUSINESS_ID=%s\n", profile.BusinessID)
+	_, _ = fmt.Fprintf(os.Stdout, "UPLOADER=%s\n", profile.Uploader)
+	_, _ = fmt.Fprintf(os.Stdout, "UPLOAD_COMMAND=%s\n", profile.UploadCommand)
+	_, _ = fmt.Fprintf(os.Stdout, "VIDEO_UPLOAD=%s\n", profile.VideoUpload)
+	keepCopyright := ""
+	if profile.KeepCopyright != nil {
+		keepCopyright = strconv.FormatBool(*profile.KeepCopyright)
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "KEEP_COPYRIGHT=%s\n", keepCopyright)
 
 	_, ok, err := secrets.GetAccessToken(name)
 	if err != nil {
@@ -191,5 +421,47 @@ func printProfile(name string, profile config.Profile) error {
 		_, _ = fmt.Fprintln(os.Stdout, "ACCESS_TOKEN=EMPTY")
 	}
 
+	if profile.S3 != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "S3_ENDPOINT=%s\n", profile.S3.Endpoint)
+		_, _ = fmt.Fprintf(os.Stdout, "S3_REGION=%s\n", profile.S3.Region)
+		_, _ = fmt.Fprintf(os.Stdout, "S3_BUCKET=%s\n", profile.S3.Bucket)
+		_, _ = fmt.Fprintf(os.Stdout, "S3_PREFIX=%s\n", profile.S3.Prefix)
+		_, _ = fmt.Fprintf(os.Stdout, "S3_ACCESS_KEY_ID=%s\n", profile.S3.AccessKeyID)
+		_, _ = fmt.Fprintf(os.Stdout, "S3_URL_EXPIRY=%s\n", profile.S3.URLExpiry)
+
+		_, ok, err = secrets.GetS3SecretAccessKey(name)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			_, _ = fmt.Fprintln(os.Stdout, "S3_SECRET_ACCESS_KEY=SET")
+		} else {
+			_, _ = fmt.Fprintln(os.Stdout, "S3_SECRET_ACCESS_KEY=EMPTY")
+		}
+	}
+
+	if profile.Retry != nil {
+		statuses := make([]string, 0, len(profile.Retry.Statuses))
+		for _, status := range profile.Retry.Statuses {
+			statuses = append(statuses, strconv.Itoa(status))
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "UPLOAD_ATTEMPTS=%d\n", profile.Retry.Attempts)
+		_, _ = fmt.Fprintf(os.Stdout, "UPLOAD_BACKOFF=%s\n", profile.Retry.BaseDelay)
+		_, _ = fmt.Fprintf(os.Stdout, "UPLOAD_MAX_BACKOFF=%s\n", profile.Retry.MaxDelay)
+		_, _ = fmt.Fprintf(os.Stdout, "UPLOAD_RETRY_STATUSES=%s\n", strings.Join(statuses, ","))
+	}
+
+	if len(profile.UguuMirrors) > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "UGUU_MIRRORS=%s\n", strings.Join(profile.UguuMirrors, ","))
+	}
+
+	if profile.Serve != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "SERVE_PUBLIC_BASE_URL=%s\n", profile.Serve.PublicBaseURL)
+		_, _ = fmt.Fprintf(os.Stdout, "SERVE_LISTEN=%s\n", profile.Serve.Listen)
+		_, _ = fmt.Fprintf(os.Stdout, "SERVE_TTL=%s\n", profile.Serve.TTL)
+	}
+
 	return nil
 }
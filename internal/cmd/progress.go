@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/mahmoudashraf93/poster/internal/progress"
+	"github.com/mahmoudashraf93/poster/internal/upload"
+)
+
+const progressRedrawInterval = 100 * time.Millisecond
+
+// uploadFile uploads path, a prepared copy of source or source itself,
+// drawing a progress line on stderr when it is a terminal.
+func uploadFile(ctx context.Context, uploader upload.Uploader, source, path string) (upload.Hosted, error) {
+	ctx, done := withProgress(ctx, path)
+	defer done()
+
+	return uploader.Upload(upload.WithSource(ctx, source), path)
+}
+
+// withProgress sets ctx up to draw a progress line for sending path on
+// stderr, and returns a func that ends the line. It does nothing when path is
+// empty or stderr is not a terminal, so redirected output stays clean.
+func withProgress(ctx context.Context, path string) (context.Context, func()) {
+	if path == "" || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return ctx, func() {}
+	}
+
+	meter := newProgressMeter(os.Stderr, filepath.Base(path))
+	return progress.With(ctx, meter.update), meter.finish
+}
+
+type progressMeter struct {
+	w       io.Writer
+	label   string
+	started time.Time
+	drawn   time.Time
+}
+
+func newProgressMeter(w io.Writer, label string) *progressMeter {
+	return &progressMeter{w: w, label: label, started: time.Now()}
+}
+
+func (m *progressMeter) update(sent, total int64) {
+	now := time.Now()
+	if sent != total && now.Sub(m.drawn) < progressRedrawInterval {
+		return
+	}
+	m.drawn = now
+
+	rate := ""
+	if elapsed := now.Sub(m.started).Seconds(); elapsed > 0 && sent > 0 {
+		rate = fmt.Sprintf(" %s/s", formatBytes(int64(float64(sent)/elapsed)))
+	}
+
+	if total > 0 {
+		_, _ = fmt.Fprintf(m.w, "\r\033[KUploading %s: %3d%% %s/%s%s",
+			m.label, sent*100/total, formatBytes(sent), formatBytes(total), rate)
+		return
+	}
+
+	_, _ = fmt.Fprintf(m.w, "\r\033[KUploading %s: %s%s", m.label, formatBytes(sent), rate)
+}
+
+func (m *progressMeter) finish() {
+	if !m.drawn.IsZero() {
+		_, _ = fmt.Fprintln(m.w)
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value := float64(n)
+	for _, suffix := range []string{"kB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+
+	return fmt.Sprintf("%d B", n)
+}
@@ -4,16 +4,31 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/mahmoudashraf93/poster/internal/config"
 	"github.com/mahmoudashraf93/poster/internal/graph"
+	"github.com/mahmoudashraf93/poster/internal/media"
 	"github.com/mahmoudashraf93/poster/internal/upload"
 )
 
 type ReelCmd struct {
-	File    string `help:"Local video file" type:"existingfile"`
-	URL     string `help:"Public HTTPS video URL (skip upload)"`
-	Caption string `help:"Post caption" short:"c"`
+	File            string   `help:"Local video file" type:"existingfile"`
+	URL             string   `help:"Public HTTPS video URL (skip upload)"`
+	Caption         string   `help:"Post caption" short:"c"`
+	LocationID      string   `help:"Facebook Place ID to tag (see: poster location search)"`
+	CoverFile       string   `help:"Local cover image file" type:"existingfile"`
+	CoverURL        string   `help:"Public HTTPS cover image URL (skip upload)"`
+	ThumbOffset     *int     `help:"Video frame to use as the cover, in milliseconds (ignored with a cover image)"`
+	ShareToFeed     bool     `help:"Also show the reel in the feed tab" default:"true" negatable:""`
+	AudioName       string   `help:"Name of the reel's original audio"`
+	Collaborators   []string `name:"collaborator" help:"Invite a collaborator by username (repeatable, max 3)"`
+	Trial           bool     `help:"Publish as a trial reel, shown to non-followers first"`
+	TrialGraduation string   `help:"Trial graduation: manual|ss_performance (default manual)" placeholder:"STRATEGY"`
+
+	FirstCommentFlags `embed:""`
+	ImageFlags        `embed:""`
+	UploadFlags       `embed:""`
 }
 
 func (c *ReelCmd) Run(root *RootFlags) error {
@@ -23,12 +38,73 @@ func (c *ReelCmd) Run(root *RootFlags) error {
 	if c.File != "" && c.URL != "" {
 		return usage("provide only one of --file or --url")
 	}
+	if c.CoverFile != "" && c.CoverURL != "" {
+		return usage("provide only one of --cover-file or --cover-url")
+	}
+	if c.ThumbOffset != nil && *c.ThumbOffset < 0 {
+		return usage("--thumb-offset cannot be negative")
+	}
+
+	trialGraduation, err := resolveTrialGraduation(c.Trial, c.TrialGraduation)
+	if err != nil {
+		return err
+	}
+
+	firstComment, err := c.FirstCommentFlags.resolve()
+	if err != nil {
+		return err
+	}
+
+	collaborators, err := parseCollaborators(c.Collaborators)
+	if err != nil {
+		return err
+	}
+
+	if c.File != "" {
+		var video media.Info
+		video, err = detectMedia(c.File)
+		if err != nil {
+			return err
+		}
+		if !video.IsVideo() {
+			return usage("--file must be a video (use poster photo for images)")
+		}
+	}
+
+	if c.CoverFile != "" {
+		var cover media.Info
+		cover, err = detectMedia(c.CoverFile)
+		if err != nil {
+			return err
+		}
+		if cover.IsVideo() {
+			return usage("--cover-file must be an image")
+		}
+	}
 
 	cfg, err := config.LoadWithProfile(root.Profile)
 	if err != nil {
 		return err
 	}
 
+	images, err := newImagePipeline(cfg, &c.ImageFlags, nil)
+	if err != nil {
+		return err
+	}
+
+	defer images.release()
+
+	coverFile := c.CoverFile
+	if coverFile != "" {
+		var prepared []string
+		prepared, err = images.prepare("", coverFile)
+		if err != nil {
+			return err
+		}
+
+		coverFile = prepared[0]
+	}
+
 	if root != nil && root.UserID != "" {
 		cfg.IGUserID = root.UserID
 	}
@@ -38,22 +114,88 @@ func (c *ReelCmd) Run(root *RootFlags) error {
 		return err
 	}
 
+	uploader, err := newUploader(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	defer releaseUploader(uploader)
+
+	resumable, err := resumableVideos(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	var uploads []upload.Hosted
+
 	ctx := context.Background()
 	mediaURL := c.URL
-	if mediaURL != "" {
+	uploadDirect := ""
+	switch {
+	case mediaURL != "":
 		mediaURL, err = ensureHTTPS(mediaURL)
 		if err != nil {
 			return err
 		}
-	} else {
-		mediaURL, err = upload.Upload(ctx, c.File)
+	case resumable:
+		uploadDirect = c.File
+	default:
+		var hosted upload.Hosted
+		hosted, err = uploadFile(ctx, uploader, c.File, c.File)
+		if err != nil {
+			return err
+		}
+		mediaURL = hosted.URL
+		uploads = append(uploads, hosted)
+	}
+
+	if uploadDirect == "" {
+		err = verifyMediaURL(ctx, &c.UploadFlags, mediaURL, true, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	coverURL := c.CoverURL
+	if coverURL != "" {
+		coverURL, err = ensureHTTPS(coverURL)
+		if err != nil {
+			return err
+		}
+	} else if coverFile != "" {
+		var hosted upload.Hosted
+		hosted, err = uploadFile(ctx, uploader, c.CoverFile, coverFile)
+		if err != nil {
+			return err
+		}
+		coverURL = hosted.URL
+		uploads = append(uploads, hosted)
+	}
+
+	if coverURL != "" {
+		err = verifyMediaURL(ctx, &c.UploadFlags, coverURL, false, nil)
 		if err != nil {
 			return err
 		}
 	}
 
+	opts := graph.ReelOptions{
+		MediaOptions: graph.MediaOptions{
+			LocationID:    c.LocationID,
+			Collaborators: collaborators,
+			UploadFile:    uploadDirect,
+		},
+		CoverURL:        coverURL,
+		ThumbOffset:     c.ThumbOffset,
+		ShareToFeed:     &c.ShareToFeed,
+		AudioName:       c.AudioName,
+		TrialGraduation: trialGraduation,
+	}
+
 	client := graph.NewClient(cfg)
-	creationID, err := client.CreateReelContainer(ctx, mediaURL, c.Caption)
+	uploadCtx, endProgress := withProgress(ctx, uploadDirect)
+	creationID, err := client.CreateReelContainer(uploadCtx, mediaURL, c.Caption, opts)
+	endProgress()
 	if err != nil {
 		return err
 	}
@@ -63,11 +205,34 @@ func (c *ReelCmd) Run(root *RootFlags) error {
 		return err
 	}
 
+	releaseUploader(uploader)
+
 	publishedID, err := client.Publish(ctx, creationID)
 	if err != nil {
 		return err
 	}
 
 	_, _ = fmt.Fprintf(os.Stdout, "PUBLISHED_MEDIA_ID=%s\n", publishedID)
-	return nil
+	cleanupHosted(ctx, &c.UploadFlags, uploader, uploads)
+
+	return postFirstComment(ctx, client, publishedID, firstComment)
+}
+
+func resolveTrialGraduation(trial bool, strategy string) (string, error) {
+	if !trial {
+		if strategy != "" {
+			return "", usage("--trial-graduation requires --trial")
+		}
+
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(strategy)) {
+	case "", "manual":
+		return graph.TrialGraduationManual, nil
+	case "ss_performance":
+		return graph.TrialGraduationPerformance, nil
+	default:
+		return "", usage(fmt.Sprintf("invalid --trial-graduation %q (expected manual or ss_performance)", strategy))
+	}
 }
@@ -27,6 +27,12 @@ type CLI struct {
 	Photo    PhotoCmd         `cmd:"" help:"Post a photo"`
 	Reel     ReelCmd          `cmd:"" help:"Post a reel"`
 	Carousel CarouselCmd      `cmd:"" help:"Post a carousel"`
+	Story    StoryCmd         `cmd:"" help:"Post a story (image or video)"`
+	Validate ValidateCmd      `cmd:"" help:"Check media against Instagram's rules without uploading"`
+	Media    MediaCmd         `cmd:"" help:"Published media utilities"`
+	Location LocationCmd      `cmd:"" help:"Location lookup"`
+	Catalog  CatalogCmd       `cmd:"" help:"Product catalog lookup (Instagram Shopping)"`
+	Cache    CacheCmd         `cmd:"" help:"Upload cache management"`
 	Token    TokenCmd         `cmd:"" help:"Token management"`
 	Account  AccountCmd       `cmd:"" help:"Account utilities"`
 	Owned    OwnedPagesCmd    `cmd:"" name:"owned-pages" help:"List pages owned by a business"`
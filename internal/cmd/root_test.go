@@ -0,0 +1,44 @@
+package cmd
+
+import "testing"
+
+func TestParseVerifyURLsFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"photo", "--url", "https://example.com/a.jpg"}, want: true},
+		{args: []string{"photo", "--no-verify-urls", "--url", "https://example.com/a.jpg"}, want: false},
+		{args: []string{"photo", "--verify-urls", "--url", "https://example.com/a.jpg"}, want: true},
+	} {
+		parser, cli, err := newParser()
+		if err != nil {
+			t.Fatalf("new parser: %v", err)
+		}
+
+		if _, err = parser.Parse(tc.args); err != nil {
+			t.Fatalf("parse %v: %v", tc.args, err)
+		}
+
+		if cli.Photo.VerifyURLs != tc.want {
+			t.Fatalf("parse %v: expected VerifyURLs=%t, got %t", tc.args, tc.want, cli.Photo.VerifyURLs)
+		}
+	}
+}
+
+func TestPublishingFlagsAreNotGlobal(t *testing.T) {
+	for _, args := range [][]string{
+		{"--uploader", "s3", "token", "debug"},
+		{"location", "search", "--query", "cafe", "--no-verify-urls"},
+		{"cache", "list", "--no-convert"},
+	} {
+		parser, _, err := newParser()
+		if err != nil {
+			t.Fatalf("new parser: %v", err)
+		}
+
+		if _, err = parser.Parse(args); err == nil {
+			t.Fatalf("parse %v: expected unknown flag error", args)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/graph"
+	"github.com/mahmoudashraf93/poster/internal/media"
+	"github.com/mahmoudashraf93/poster/internal/upload"
+)
+
+// StoryCmd has no FirstCommentFlags: the Graph API cannot comment on
+// stories.
+type StoryCmd struct {
+	File       string `help:"Local image or video file" type:"existingfile"`
+	URL        string `help:"Public HTTPS image or video URL (skip upload)"`
+	LocationID string `help:"Facebook Place ID to tag (see: poster location search)"`
+
+	FitFlags    `embed:""`
+	ImageFlags  `embed:""`
+	UploadFlags `embed:""`
+}
+
+func (c *StoryCmd) Run(root *RootFlags) error {
+	if c.File == "" && c.URL == "" {
+		return usage("provide --file or --url")
+	}
+	if c.File != "" && c.URL != "" {
+		return usage("provide only one of --file or --url")
+	}
+
+	ctx := context.Background()
+
+	var info media.Info
+	var probed *upload.URLInfo
+	var err error
+	if c.File != "" {
+		info, err = detectMedia(c.File)
+	} else {
+		info, probed, err = probeURLMedia(ctx, c.URL)
+	}
+	if err != nil {
+		return err
+	}
+
+	isVideo := info.IsVideo()
+
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	images, err := newImagePipeline(cfg, &c.ImageFlags, &c.FitFlags)
+	if err != nil {
+		return err
+	}
+
+	defer images.release()
+
+	file := c.File
+	if file != "" && !isVideo {
+		var prepared []string
+		prepared, err = images.prepare(media.TargetStory, file)
+		if err != nil {
+			return err
+		}
+
+		file = prepared[0]
+	}
+
+	if root != nil && root.UserID != "" {
+		cfg.IGUserID = root.UserID
+	}
+
+	err = cfg.ValidateForAccessToken()
+	if err != nil {
+		return err
+	}
+
+	uploader, err := newUploader(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	defer releaseUploader(uploader)
+
+	resumable, err := resumableVideos(cfg, &c.UploadFlags)
+	if err != nil {
+		return err
+	}
+
+	var uploads []upload.Hosted
+
+	mediaURL := c.URL
+	uploadDirect := ""
+	switch {
+	case mediaURL != "":
+		mediaURL, err = ensureHTTPS(mediaURL)
+		if err != nil {
+			return err
+		}
+	case isVideo && resumable:
+		uploadDirect = c.File
+	default:
+		var hosted upload.Hosted
+		hosted, err = uploadFile(ctx, uploader, c.File, file)
+		if err != nil {
+			return err
+		}
+		mediaURL = hosted.URL
+		uploads = append(uploads, hosted)
+	}
+
+	if uploadDirect == "" {
+		err = verifyMediaURL(ctx, &c.UploadFlags, mediaURL, isVideo, probed)
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := graph.MediaOptions{
+		LocationID: c.LocationID,
+		UploadFile: uploadDirect,
+	}
+
+	client := graph.NewClient(cfg)
+	uploadCtx, endProgress := withProgress(ctx, uploadDirect)
+	creationID, err := client.CreateStoryContainer(uploadCtx, mediaURL, isVideo, opts)
+	endProgress()
+	if err != nil {
+		return err
+	}
+
+	if isVideo || (c.File != "" && servesLocally(uploader)) {
+		err = client.PollStatus(ctx, creationID, cfg.PollInterval, cfg.PollTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	releaseUploader(uploader)
+
+	publishedID, err := client.Publish(ctx, creationID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "PUBLISHED_MEDIA_ID=%s\n", publishedID)
+	cleanupHosted(ctx, &c.UploadFlags, uploader, uploads)
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/graph"
+)
+
+// parseUserTag parses "@username:x,y" where x and y are in the 0-1 range.
+func parseUserTag(raw string) (graph.UserTag, error) {
+	value := strings.TrimSpace(raw)
+
+	name, coords, ok := strings.Cut(value, ":")
+	if !ok {
+		return graph.UserTag{}, usage(fmt.Sprintf("invalid tag %q (expected @username:x,y)", raw))
+	}
+
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if !validUsername(name) {
+		return graph.UserTag{}, usage(fmt.Sprintf("invalid tag %q: bad username", raw))
+	}
+
+	x, y, err := parsePosition(coords)
+	if err != nil {
+		return graph.UserTag{}, usage(fmt.Sprintf("invalid tag %q: %v", raw, err))
+	}
+
+	return graph.UserTag{Username: name, X: x, Y: y}, nil
+}
+
+func parseUserTags(raw []string) ([]graph.UserTag, error) {
+	tags := make([]graph.UserTag, 0, len(raw))
+	for _, entry := range raw {
+		tag, err := parseUserTag(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// parseFileUserTags parses carousel tags of the form "file=@username:x,y",
+// keyed by the index of the file in files.
+func parseFileUserTags(raw, files []string) (map[int][]graph.UserTag, error) {
+	tags := make(map[int][]graph.UserTag)
+	for _, entry := range raw {
+		idx, value, err := splitFileMapping(entry, files)
+		if err != nil {
+			return nil, err
+		}
+
+		tag, err := parseUserTag(value)
+		if err != nil {
+			return nil, err
+		}
+
+		tags[idx] = append(tags[idx], tag)
+	}
+
+	return tags, nil
+}
+
+// parsePosition parses "x,y" and checks both values are in the 0-1 range.
+func parsePosition(raw string) (x, y float64, err error) {
+	xs, ys, ok := strings.Cut(raw, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected x,y coordinates")
+	}
+
+	x, err = strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid x coordinate %q", xs)
+	}
+
+	y, err = strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid y coordinate %q", ys)
+	}
+
+	if x < 0 || x > 1 || y < 0 || y > 1 {
+		return 0, 0, fmt.Errorf("coordinates must be between 0 and 1")
+	}
+
+	return x, y, nil
+}
+
+// maxCollaborators is Instagram's limit on collaborator invites per post.
+const maxCollaborators = 3
+
+func parseCollaborators(raw []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(raw))
+	usernames := make([]string, 0, len(raw))
+
+	for _, entry := range raw {
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry), "@"))
+		if !validUsername(name) {
+			return nil, usage(fmt.Sprintf("invalid collaborator username %q", entry))
+		}
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		usernames = append(usernames, name)
+	}
+
+	if len(usernames) > maxCollaborators {
+		return nil, usage(fmt.Sprintf("too many collaborators: %d (Instagram allows at most %d)", len(usernames), maxCollaborators))
+	}
+
+	return usernames, nil
+}
+
+// validUsername reports whether name looks like an Instagram username:
+// 1-30 letters, digits, periods or underscores.
+func validUsername(name string) bool {
+	if name == "" || len(name) > 30 {
+		return false
+	}
+
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '_' {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// maxProductTags is Instagram's limit on product tags per image.
+const maxProductTags = 5
+
+// parseProductTag parses "<product_id>:x,y" where x and y are in the 0-1 range.
+func parseProductTag(raw string) (graph.ProductTag, error) {
+	id, coords, ok := strings.Cut(strings.TrimSpace(raw), ":")
+	if !ok {
+		return graph.ProductTag{}, usage(fmt.Sprintf("invalid product tag %q (expected <product_id>:x,y)", raw))
+	}
+
+	id = strings.TrimSpace(id)
+	if !validProductID(id) {
+		return graph.ProductTag{}, usage(fmt.Sprintf("invalid product tag %q: bad product id", raw))
+	}
+
+	x, y, err := parsePosition(coords)
+	if err != nil {
+		return graph.ProductTag{}, usage(fmt.Sprintf("invalid product tag %q: %v", raw, err))
+	}
+
+	return graph.ProductTag{ProductID: id, X: x, Y: y}, nil
+}
+
+func parseProductTags(raw []string) ([]graph.ProductTag, error) {
+	if len(raw) > maxProductTags {
+		return nil, usage(fmt.Sprintf("too many product tags: %d (Instagram allows at most %d per image)", len(raw), maxProductTags))
+	}
+
+	tags := make([]graph.ProductTag, 0, len(raw))
+	for _, entry := range raw {
+		tag, err := parseProductTag(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// parseFileProductTags parses carousel product tags of the form
+// "file=<product_id>:x,y", keyed by the index of the file in files.
+func parseFileProductTags(raw, files []string) (map[int][]graph.ProductTag, error) {
+	tags := make(map[int][]graph.ProductTag)
+	for _, entry := range raw {
+		idx, value, err := splitFileMapping(entry, files)
+		if err != nil {
+			return nil, err
+		}
+
+		tag, err := parseProductTag(value)
+		if err != nil {
+			return nil, err
+		}
+
+		tags[idx] = append(tags[idx], tag)
+		if len(tags[idx]) > maxProductTags {
+			return nil, usage(fmt.Sprintf("too many product tags on %s (Instagram allows at most %d per image)", files[idx], maxProductTags))
+		}
+	}
+
+	return tags, nil
+}
+
+func validProductID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/upload"
+)
+
+// UploadFlags choose where local media is hosted for Meta to fetch and how
+// media URLs are checked. They are embedded in the publishing commands.
+type UploadFlags struct {
+	Uploader      string `help:"Media hosting backend for local files (overrides profile and POSTER_UPLOADER)"`
+	PublicBaseURL string `help:"Public HTTPS URL that reaches the serve uploader's listener (overrides profile and POSTER_PUBLIC_BASE_URL)"`
+	VideoUpload   string `help:"How local videos reach Meta: hosted (via the uploader) or resumable (sent directly) (overrides profile and POSTER_VIDEO_UPLOAD)"`
+	KeepHosted    bool   `help:"Leave uploaded media on the hosting backend after publishing"`
+	VerifyURLs    bool   `name:"verify-urls" help:"Check that media URLs are fetchable before creating containers" default:"true" negatable:""`
+}
+
+// newUploader returns the hosting backend selected by --uploader, the profile
+// or POSTER_UPLOADER, in that order, behind the upload cache.
+func newUploader(cfg *config.Config, flags *UploadFlags) (upload.Uploader, error) {
+	uploader, err := newBackend(cfg, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files served by this process vanish when it exits, so there is
+	// nothing worth caching.
+	if servesLocally(uploader) {
+		return uploader, nil
+	}
+
+	cache, err := newUploadCache()
+	if err != nil {
+		slog.Warn("upload cache disabled", "err", err)
+		return uploader, nil
+	}
+
+	return upload.WithCache(uploader, cache), nil
+}
+
+func newBackend(cfg *config.Config, flags *UploadFlags) (upload.Uploader, error) {
+	name := cfg.Uploader
+	if flags != nil && flags.Uploader != "" {
+		name = flags.Uploader
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = upload.DefaultBackend
+	}
+
+	retry := upload.RetryPolicy{
+		Attempts:          cfg.Retry.Attempts,
+		BaseDelay:         cfg.Retry.BaseDelay,
+		MaxDelay:          cfg.Retry.MaxDelay,
+		RetryableStatuses: cfg.Retry.Statuses,
+	}
+
+	switch name {
+	case upload.BackendUguu:
+		uguu := upload.NewUguu()
+		uguu.Fallbacks = cfg.UguuMirrors
+		uguu.Retry = retry
+		return uguu, nil
+	case upload.BackendS3:
+		s3, err := upload.NewS3(upload.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			Prefix:          cfg.S3.Prefix,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			URLExpiry:       cfg.S3.URLExpiry,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s3.Retry = retry
+		return s3, nil
+	case upload.BackendServe:
+		publicBaseURL := cfg.Serve.PublicBaseURL
+		if flags != nil && flags.PublicBaseURL != "" {
+			publicBaseURL = flags.PublicBaseURL
+		}
+
+		if publicBaseURL == "" {
+			return nil, usage("the serve uploader needs --public-base-url (or a profile or POSTER_PUBLIC_BASE_URL setting)")
+		}
+
+		return upload.NewServer(upload.ServeConfig{
+			PublicBaseURL: publicBaseURL,
+			ListenAddr:    cfg.Serve.ListenAddr,
+			TTL:           cfg.Serve.TTL,
+		})
+	case upload.BackendCommand:
+		if cfg.UploadCommand == "" {
+			return nil, usage("the command uploader needs --profile-upload-command (or POSTER_UPLOAD_COMMAND)")
+		}
+
+		return upload.NewCommand(cfg.UploadCommand)
+	default:
+		if command, ok := upload.LookupCommand(name); ok {
+			return command, nil
+		}
+
+		return nil, usage(fmt.Sprintf("unknown uploader %q (expected %s, or %s%s on PATH)",
+			name, strings.Join(uploaderNames(), ", "), upload.CommandPrefix, name))
+	}
+}
+
+func newUploadCache() (*upload.Cache, error) {
+	path, err := config.UploadCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	return upload.NewCache(path), nil
+}
+
+func uploaderNames() []string {
+	return []string{upload.BackendUguu, upload.BackendS3, upload.BackendServe, upload.BackendCommand}
+}
+
+// servesLocally reports whether uploader serves files from this process, so
+// poster has to wait for Meta to fetch them before moving on.
+func servesLocally(uploader upload.Uploader) bool {
+	_, ok := uploader.(io.Closer)
+	return ok
+}
+
+// releaseUploader stops backends that serve files from this process. It is
+// safe to call more than once and a no-op for other backends.
+func releaseUploader(uploader upload.Uploader) {
+	closer, ok := uploader.(io.Closer)
+	if !ok {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		slog.Warn("stop uploader", "backend", uploader.Name(), "err", err)
+	}
+}
+
+// cleanupHosted removes the hosted copies of published media unless
+// --keep-hosted is set. A failed delete does not fail the command; it is
+// reported as a CLEANUP_WARNING line.
+func cleanupHosted(ctx context.Context, flags *UploadFlags, uploader upload.Uploader, uploads []upload.Hosted) {
+	if len(uploads) == 0 || (flags != nil && flags.KeepHosted) {
+		return
+	}
+
+	deleter, ok := uploader.(upload.Deleter)
+	if !ok {
+		slog.Debug("uploader cannot delete hosted files", "backend", uploader.Name())
+		return
+	}
+
+	for _, hosted := range uploads {
+		if err := deleter.Delete(ctx, hosted); err != nil {
+			_, _ = fmt.Fprintf(os.Stdout, "CLEANUP_WARNING=could not delete %s: %v\n", redactURL(hosted.URL), err)
+		}
+	}
+}
+
+// Ways a local video can reach Meta.
+const (
+	videoUploadHosted    = "hosted"
+	videoUploadResumable = "resumable"
+)
+
+// resumableVideos reports whether local videos are sent straight to Meta with
+// a resumable upload instead of going through the uploader. It is selected by
+// --video-upload, the profile or POSTER_VIDEO_UPLOAD, in that order.
+func resumableVideos(cfg *config.Config, flags *UploadFlags) (bool, error) {
+	mode := cfg.VideoUpload
+	if flags != nil && flags.VideoUpload != "" {
+		mode = flags.VideoUpload
+	}
+
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", videoUploadHosted:
+		return false, nil
+	case videoUploadResumable:
+		return true, nil
+	default:
+		return false, usage(fmt.Sprintf("invalid video upload mode %q (expected %s or %s)", mode, videoUploadHosted, videoUploadResumable))
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mahmoudashraf93/poster/internal/config"
+	"github.com/mahmoudashraf93/poster/internal/media"
+)
+
+type ValidateCmd struct {
+	Files  []string `arg:"" name:"files" help:"Local media files" type:"existingfile"`
+	Target string   `help:"Where the media will be published: feed|carousel|story" default:"feed"`
+
+	FitFlags     `embed:""`
+	ConvertFlags `embed:""`
+}
+
+// Run applies the checks photo, carousel and story run before uploading,
+// including conversion, without uploading anything.
+func (c *ValidateCmd) Run(root *RootFlags) error {
+	target := media.Target(strings.ToLower(strings.TrimSpace(c.Target)))
+	if _, ok := media.SpecFor(target); !ok {
+		names := make([]string, 0, len(media.Targets()))
+		for _, t := range media.Targets() {
+			names = append(names, string(t))
+		}
+
+		return usage(fmt.Sprintf("invalid --target %q (expected %s)", c.Target, strings.Join(names, "|")))
+	}
+
+	cfg, err := config.LoadWithProfile(root.Profile)
+	if err != nil {
+		return err
+	}
+
+	images, err := newImagePipeline(cfg, &ImageFlags{Validate: true, ConvertFlags: c.ConvertFlags}, &c.FitFlags)
+	if err != nil {
+		return err
+	}
+
+	defer images.release()
+
+	failed := 0
+	for _, path := range c.Files {
+		if !printValidation(images, path, target) {
+			failed++
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, "---")
+	}
+
+	if failed > 0 {
+		return invalidMedia(fmt.Errorf("%d of %d files failed validation", failed, len(c.Files)))
+	}
+
+	return nil
+}
+
+// printValidation prints the result for one file and reports whether it
+// passed.
+func printValidation(images *imagePipeline, path string, target media.Target) bool {
+	_, _ = fmt.Fprintf(os.Stdout, "FILE=%s\n", path)
+	_, _ = fmt.Fprintf(os.Stdout, "TARGET=%s\n", target)
+
+	info, err := detectMedia(path)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stdout, "VALID=false")
+		_, _ = fmt.Fprintf(os.Stdout, "VIOLATION=format: %v\n", err)
+		return false
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "KIND=%s\n", info.Kind)
+	_, _ = fmt.Fprintf(os.Stdout, "FORMAT=%s\n", info.Container)
+
+	if info.IsVideo() {
+		_, _ = fmt.Fprintln(os.Stdout, "SKIPPED=only images are checked")
+		return true
+	}
+
+	checked, converted, err := images.convertFile(path, target)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stdout, "VALID=false")
+		_, _ = fmt.Fprintf(os.Stdout, "VIOLATION=format: %v\n", err)
+		return false
+	}
+
+	if converted {
+		_, _ = fmt.Fprintf(os.Stdout, "CONVERTED_TO=%s\n", media.ContainerJPEG)
+	}
+
+	if meta, err := media.ReadMetadata(path); err == nil && meta.Present {
+		_, _ = fmt.Fprintln(os.Stdout, "METADATA_STRIPPED=true")
+	}
+
+	img, violations, err := media.ValidateImage(checked, target)
+
+	var validationErr *media.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		_, _ = fmt.Fprintln(os.Stdout, "VALID=false")
+		_, _ = fmt.Fprintf(os.Stdout, "VIOLATION=format: %v\n", err)
+		return false
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "WIDTH=%d\n", img.Width)
+	_, _ = fmt.Fprintf(os.Stdout, "HEIGHT=%d\n", img.Height)
+	_, _ = fmt.Fprintf(os.Stdout, "ASPECT_RATIO=%.2f\n", img.Aspect())
+	_, _ = fmt.Fprintf(os.Stdout, "SIZE_BYTES=%d\n", img.Size)
+	_, _ = fmt.Fprintf(os.Stdout, "VALID=%t\n", err == nil)
+
+	for _, v := range violations {
+		key := "VIOLATION"
+		if v.Warning {
+			key = "WARNING"
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "%s=%s\n", key, v)
+	}
+
+	return err == nil
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +29,22 @@ const (
 	envGraphVersion = "IG_GRAPH_VERSION"
 	envPollInterval = "IG_POLL_INTERVAL"
 	envPollTimeout  = "IG_POLL_TIMEOUT"
+	envUploader     = "POSTER_UPLOADER"
+
+	envUploadCommand = "POSTER_UPLOAD_COMMAND"
+	envVideoUpload   = "POSTER_VIDEO_UPLOAD"
+	envKeepCopyright = "POSTER_KEEP_COPYRIGHT"
+
+	envPublicBaseURL = "POSTER_PUBLIC_BASE_URL"
+	envServeListen   = "POSTER_SERVE_LISTEN"
+	envServeTTL      = "POSTER_SERVE_TTL"
+
+	envUploadAttempts = "POSTER_UPLOAD_ATTEMPTS"
+	envUguuMirrors    = "POSTER_UGUU_MIRRORS"
+
+	envAWSAccessKeyID     = "AWS_ACCESS_KEY_ID"
+	envAWSSecretAccessKey = "AWS_SECRET_ACCESS_KEY" // #nosec G101 -- env var name, not a credential
+	envAWSRegion          = "AWS_REGION"
 )
 
 type Config struct {
@@ -40,6 +57,46 @@ type Config struct {
 	GraphVersion string
 	PollInterval time.Duration
 	PollTimeout  time.Duration
+	Uploader     string
+	S3           S3Settings
+	Serve        ServeSettings
+	Retry        RetrySettings
+	UguuMirrors  []string
+
+	// UploadCommand is the executable run by the "command" uploader.
+	UploadCommand string
+	// VideoUpload is how local videos reach Meta: "hosted" or "resumable".
+	VideoUpload string
+	// KeepCopyright keeps the EXIF artist and copyright when metadata is
+	// stripped from images before uploading.
+	KeepCopyright bool
+}
+
+// S3Settings configures the s3 uploader.
+type S3Settings struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	URLExpiry       time.Duration
+}
+
+// ServeSettings configures the serve uploader.
+type ServeSettings struct {
+	PublicBaseURL string
+	ListenAddr    string
+	TTL           time.Duration
+}
+
+// RetrySettings configures upload retries. Zero values use the uploader
+// defaults.
+type RetrySettings struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Statuses  []int
 }
 
 var errConfigNil = errors.New("config is nil")
@@ -71,6 +128,18 @@ func Load() (*Config, error) {
 		GraphVersion: DefaultGraphVersion,
 		PollInterval: DefaultPollInterval,
 		PollTimeout:  DefaultPollTimeout,
+		Uploader:     os.Getenv(envUploader),
+		S3: S3Settings{
+			Region:          os.Getenv(envAWSRegion),
+			AccessKeyID:     os.Getenv(envAWSAccessKeyID),
+			SecretAccessKey: os.Getenv(envAWSSecretAccessKey),
+		},
+		Serve: ServeSettings{
+			PublicBaseURL: os.Getenv(envPublicBaseURL),
+			ListenAddr:    os.Getenv(envServeListen),
+		},
+		UploadCommand: os.Getenv(envUploadCommand),
+		VideoUpload:   os.Getenv(envVideoUpload),
 	}
 
 	if v := os.Getenv(envGraphVersion); v != "" {
@@ -93,6 +162,34 @@ func Load() (*Config, error) {
 		cfg.PollTimeout = timeout
 	}
 
+	if v := os.Getenv(envServeTTL); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envServeTTL, err)
+		}
+		cfg.Serve.TTL = ttl
+	}
+
+	if v := os.Getenv(envUploadAttempts); v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil || attempts < 1 {
+			return nil, fmt.Errorf("invalid %s: must be a positive integer", envUploadAttempts)
+		}
+		cfg.Retry.Attempts = attempts
+	}
+
+	if v := os.Getenv(envUguuMirrors); v != "" {
+		cfg.UguuMirrors = SplitList(v)
+	}
+
+	if v := os.Getenv(envKeepCopyright); v != "" {
+		keep, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be true or false", envKeepCopyright)
+		}
+		cfg.KeepCopyright = keep
+	}
+
 	return cfg, nil
 }
 
@@ -124,6 +221,44 @@ func LoadWithProfile(profile string) (*Config, error) {
 		if p.BusinessID != "" {
 			cfg.BusinessID = p.BusinessID
 		}
+
+		if p.Uploader != "" {
+			cfg.Uploader = p.Uploader
+		}
+
+		if p.UploadCommand != "" {
+			cfg.UploadCommand = p.UploadCommand
+		}
+
+		if p.VideoUpload != "" {
+			cfg.VideoUpload = p.VideoUpload
+		}
+
+		if p.KeepCopyright != nil {
+			cfg.KeepCopyright = *p.KeepCopyright
+		}
+
+		if p.S3 != nil {
+			if err := applyS3Profile(&cfg.S3, *p.S3); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+		}
+
+		if p.Serve != nil {
+			if err := applyServeProfile(&cfg.Serve, *p.Serve); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+		}
+
+		if p.Retry != nil {
+			if err := applyRetryProfile(&cfg.Retry, *p.Retry); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+		}
+
+		if len(p.UguuMirrors) > 0 {
+			cfg.UguuMirrors = p.UguuMirrors
+		}
 	}
 
 	token, ok, err := secrets.GetAccessToken(name)
@@ -135,9 +270,108 @@ func LoadWithProfile(profile string) (*Config, error) {
 		cfg.AccessToken = token
 	}
 
+	s3Secret, ok, err := secrets.GetS3SecretAccessKey(name)
+	if err != nil {
+		return nil, fmt.Errorf("load s3 secret access key: %w", err)
+	}
+
+	if ok {
+		cfg.S3.SecretAccessKey = s3Secret
+	}
+
 	return cfg, nil
 }
 
+func applyS3Profile(settings *S3Settings, p S3Profile) error {
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&settings.Endpoint, p.Endpoint},
+		{&settings.Region, p.Region},
+		{&settings.Bucket, p.Bucket},
+		{&settings.Prefix, p.Prefix},
+		{&settings.AccessKeyID, p.AccessKeyID},
+	} {
+		if field.src != "" {
+			*field.dst = field.src
+		}
+	}
+
+	if p.URLExpiry != "" {
+		expiry, err := time.ParseDuration(p.URLExpiry)
+		if err != nil {
+			return fmt.Errorf("invalid s3 url_expiry: %w", err)
+		}
+		settings.URLExpiry = expiry
+	}
+
+	return nil
+}
+
+func applyServeProfile(settings *ServeSettings, p ServeProfile) error {
+	if p.PublicBaseURL != "" {
+		settings.PublicBaseURL = p.PublicBaseURL
+	}
+
+	if p.Listen != "" {
+		settings.ListenAddr = p.Listen
+	}
+
+	if p.TTL != "" {
+		ttl, err := time.ParseDuration(p.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid serve ttl: %w", err)
+		}
+		settings.TTL = ttl
+	}
+
+	return nil
+}
+
+func applyRetryProfile(settings *RetrySettings, p RetryProfile) error {
+	if p.Attempts > 0 {
+		settings.Attempts = p.Attempts
+	}
+
+	for _, field := range []struct {
+		name string
+		dst  *time.Duration
+		src  string
+	}{
+		{"base_delay", &settings.BaseDelay, p.BaseDelay},
+		{"max_delay", &settings.MaxDelay, p.MaxDelay},
+	} {
+		if field.src == "" {
+			continue
+		}
+
+		delay, err := time.ParseDuration(field.src)
+		if err != nil {
+			return fmt.Errorf("invalid upload_retry %s: %w", field.name, err)
+		}
+		*field.dst = delay
+	}
+
+	if len(p.Statuses) > 0 {
+		settings.Statuses = p.Statuses
+	}
+
+	return nil
+}
+
+// SplitList splits a comma-separated list, dropping empty entries.
+func SplitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
 func (c *Config) Validate() error {
 	if c == nil {
 		return errConfigNil
@@ -205,6 +439,23 @@ func (c *Config) ValidateForBusinessLookup() error {
 	return nil
 }
 
+// ValidateForGraphLookup checks the settings for read-only Graph API calls
+// that only need a token, such as location search and media lookups.
+func (c *Config) ValidateForGraphLookup() error {
+	if c == nil {
+		return errConfigNil
+	}
+
+	missing := missingEnvs([]requiredEnv{
+		{name: envAccessToken, value: c.AccessToken},
+	})
+	if len(missing) > 0 {
+		return &MissingEnvError{Missing: missing}
+	}
+
+	return nil
+}
+
 func (c *Config) ValidateForTokenExchange() error {
 	if c == nil {
 		return errConfigNil
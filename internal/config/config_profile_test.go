@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/99designs/keyring"
 
@@ -37,13 +38,39 @@ func TestLoadWithProfileOverridesEnv(t *testing.T) {
 	t.Setenv("IG_USER_ID", "env-user")
 	t.Setenv("IG_PAGE_ID", "env-page")
 	t.Setenv("IG_BUSINESS_ID", "env-biz")
-
+	t.Setenv("POSTER_UPLOADER", "env-uploader")
+	t.Setenv("POSTER_PUBLIC_BASE_URL", "https://env.example.com")
+	t.Setenv("POSTER_SERVE_LISTEN", "127.0.0.1:9000")
+	t.Setenv("POSTER_UPLOAD_ATTEMPTS", "7")
+	t.Setenv("POSTER_UGUU_MIRRORS", "https://env-mirror.example.com/upload")
+	t.Setenv("POSTER_KEEP_COPYRIGHT", "true")
+
+	keep := false
 	profiles := ProfilesFile{
 		Profiles: map[string]Profile{
 			"agent": {
-				IGUserID:   "profile-user",
-				PageID:     "profile-page",
-				BusinessID: "profile-biz",
+				IGUserID:      "profile-user",
+				PageID:        "profile-page",
+				BusinessID:    "profile-biz",
+				Uploader:      "profile-uploader",
+				UploadCommand: "/usr/local/bin/cdn-upload",
+				VideoUpload:   "resumable",
+				KeepCopyright: &keep,
+				S3: &S3Profile{
+					Bucket:      "media",
+					Region:      "eu-west-1",
+					AccessKeyID: "profile-akid",
+					URLExpiry:   "30m",
+				},
+				Serve: &ServeProfile{
+					PublicBaseURL: "https://media.example.com",
+					TTL:           "10m",
+				},
+				Retry: &RetryProfile{
+					BaseDelay: "2s",
+					Statuses:  []int{500, 503},
+				},
+				UguuMirrors: []string{"https://a.example.com/upload", "https://b.example.com/upload"},
 			},
 		},
 	}
@@ -56,6 +83,10 @@ func TestLoadWithProfileOverridesEnv(t *testing.T) {
 		t.Fatalf("set access token: %v", err)
 	}
 
+	if err := secrets.SetS3SecretAccessKey("agent", "keychain-s3-secret"); err != nil {
+		t.Fatalf("set s3 secret: %v", err)
+	}
+
 	cfg, err := LoadWithProfile("Agent")
 	if err != nil {
 		t.Fatalf("load with profile: %v", err)
@@ -84,6 +115,50 @@ func TestLoadWithProfileOverridesEnv(t *testing.T) {
 	if cfg.BusinessID != "profile-biz" {
 		t.Fatalf("unexpected business id: %s", cfg.BusinessID)
 	}
+
+	if cfg.Uploader != "profile-uploader" {
+		t.Fatalf("unexpected uploader: %s", cfg.Uploader)
+	}
+
+	if cfg.UploadCommand != "/usr/local/bin/cdn-upload" {
+		t.Fatalf("unexpected upload command: %s", cfg.UploadCommand)
+	}
+
+	if cfg.VideoUpload != "resumable" {
+		t.Fatalf("unexpected video upload: %s", cfg.VideoUpload)
+	}
+
+	if cfg.KeepCopyright {
+		t.Fatalf("expected profile keep_copyright false to override env")
+	}
+
+	expectedS3 := S3Settings{
+		Bucket:          "media",
+		Region:          "eu-west-1",
+		AccessKeyID:     "profile-akid",
+		SecretAccessKey: "keychain-s3-secret",
+		URLExpiry:       30 * time.Minute,
+	}
+	if cfg.S3 != expectedS3 {
+		t.Fatalf("unexpected s3 settings: %#v", cfg.S3)
+	}
+
+	expectedServe := ServeSettings{
+		PublicBaseURL: "https://media.example.com",
+		ListenAddr:    "127.0.0.1:9000",
+		TTL:           10 * time.Minute,
+	}
+	if cfg.Serve != expectedServe {
+		t.Fatalf("unexpected serve settings: %#v", cfg.Serve)
+	}
+
+	if cfg.Retry.Attempts != 7 || cfg.Retry.BaseDelay != 2*time.Second || len(cfg.Retry.Statuses) != 2 {
+		t.Fatalf("unexpected retry settings: %#v", cfg.Retry)
+	}
+
+	if len(cfg.UguuMirrors) != 2 || cfg.UguuMirrors[0] != "https://a.example.com/upload" {
+		t.Fatalf("unexpected uguu mirrors: %v", cfg.UguuMirrors)
+	}
 }
 
 func TestLoadWithProfileFallsBackToEnv(t *testing.T) {
@@ -98,6 +173,7 @@ func TestLoadWithProfileFallsBackToEnv(t *testing.T) {
 	t.Setenv("IG_USER_ID", "env-user")
 	t.Setenv("IG_PAGE_ID", "env-page")
 	t.Setenv("IG_BUSINESS_ID", "env-biz")
+	t.Setenv("POSTER_KEEP_COPYRIGHT", "1")
 
 	cfg, err := LoadWithProfile("missing")
 	if err != nil {
@@ -119,4 +195,8 @@ func TestLoadWithProfileFallsBackToEnv(t *testing.T) {
 	if cfg.BusinessID != "env-biz" {
 		t.Fatalf("unexpected business id: %s", cfg.BusinessID)
 	}
+
+	if !cfg.KeepCopyright {
+		t.Fatalf("expected keep copyright from env")
+	}
 }
@@ -14,6 +14,7 @@ const (
 	AppName            = "poster"
 	DefaultProfileName = "default"
 	profilesConfigFile = "config.json"
+	uploadCacheFile    = "upload-cache.json"
 )
 
 var errInvalidProfileName = errors.New("invalid profile name")
@@ -23,6 +24,48 @@ type Profile struct {
 	IGUserID   string `json:"ig_user_id,omitempty"`
 	PageID     string `json:"page_id,omitempty"`
 	BusinessID string `json:"business_id,omitempty"`
+	Uploader   string `json:"uploader,omitempty"`
+
+	// UploadCommand is the executable run by the "command" uploader.
+	UploadCommand string `json:"upload_command,omitempty"`
+	// VideoUpload is how local videos reach Meta: "hosted" or "resumable".
+	VideoUpload string `json:"video_upload,omitempty"`
+	// KeepCopyright keeps the EXIF artist and copyright when metadata is
+	// stripped from images before uploading. Unset falls back to
+	// POSTER_KEEP_COPYRIGHT.
+	KeepCopyright *bool `json:"keep_copyright,omitempty"`
+
+	S3    *S3Profile    `json:"s3,omitempty"`
+	Serve *ServeProfile `json:"serve,omitempty"`
+
+	Retry       *RetryProfile `json:"upload_retry,omitempty"`
+	UguuMirrors []string      `json:"uguu_mirrors,omitempty"`
+}
+
+// S3Profile configures the s3 uploader. The secret access key is stored in
+// the keyring, not here.
+type S3Profile struct {
+	Endpoint    string `json:"endpoint,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Bucket      string `json:"bucket,omitempty"`
+	Prefix      string `json:"prefix,omitempty"`
+	AccessKeyID string `json:"access_key_id,omitempty"`
+	URLExpiry   string `json:"url_expiry,omitempty"`
+}
+
+// ServeProfile configures the serve uploader.
+type ServeProfile struct {
+	PublicBaseURL string `json:"public_base_url,omitempty"`
+	Listen        string `json:"listen,omitempty"`
+	TTL           string `json:"ttl,omitempty"`
+}
+
+// RetryProfile configures upload retries. Delays are Go durations.
+type RetryProfile struct {
+	Attempts  int    `json:"attempts,omitempty"`
+	BaseDelay string `json:"base_delay,omitempty"`
+	MaxDelay  string `json:"max_delay,omitempty"`
+	Statuses  []int  `json:"statuses,omitempty"`
 }
 
 type ProfilesFile struct {
@@ -61,6 +104,16 @@ func ProfilesPath() (string, error) {
 	return filepath.Join(dir, profilesConfigFile), nil
 }
 
+// UploadCachePath is where hosted URLs are remembered between runs.
+func UploadCachePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, uploadCacheFile), nil
+}
+
 func ReadProfiles() (ProfilesFile, error) {
 	path, err := ProfilesPath()
 	if err != nil {
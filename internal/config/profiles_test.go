@@ -28,12 +28,14 @@ func TestProfilesReadWrite(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", base)
 	t.Setenv("HOME", base)
 
+	keep := true
 	cfg := ProfilesFile{
 		Profiles: map[string]Profile{
 			"default": {
-				IGUserID:   "ig1",
-				PageID:     "page1",
-				BusinessID: "biz1",
+				IGUserID:      "ig1",
+				PageID:        "page1",
+				BusinessID:    "biz1",
+				KeepCopyright: &keep,
 			},
 		},
 	}
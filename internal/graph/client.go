@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -14,9 +15,34 @@ import (
 
 type JSON map[string]any
 
+// parseJSON decodes a response body. Numbers are kept as json.Number, so
+// numeric IDs longer than a float64 holds exactly are not rounded.
+func parseJSON(payload []byte) (JSON, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var parsed JSON
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// decodeJSON converts a decoded response into a typed struct.
+func decodeJSON(payload JSON, target any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, target)
+}
+
 type Client struct {
 	httpClient   *http.Client
 	baseURL      string
+	ruploadURL   string
 	graphVersion string
 	accessToken  string
 	igUserID     string
@@ -31,6 +57,7 @@ func NewClient(cfg *config.Config) *Client {
 	return &Client{
 		httpClient:   http.DefaultClient,
 		baseURL:      "https://graph.facebook.com/",
+		ruploadURL:   defaultRuploadURL,
 		graphVersion: version,
 		accessToken:  cfg.AccessToken,
 		igUserID:     cfg.IGUserID,
@@ -45,6 +72,10 @@ func (c *Client) get(ctx context.Context, path string, params map[string]string)
 	return c.do(ctx, http.MethodGet, path, params)
 }
 
+func (c *Client) delete(ctx context.Context, path string, params map[string]string) (JSON, error) {
+	return c.do(ctx, http.MethodDelete, path, params)
+}
+
 func (c *Client) do(ctx context.Context, method, path string, params map[string]string) (JSON, error) {
 	if c == nil {
 		return nil, ErrGraphClientNil
@@ -62,7 +93,7 @@ func (c *Client) do(ctx context.Context, method, path string, params map[string]
 	endpoint := c.endpoint(path)
 	var body io.Reader
 
-	if method == http.MethodGet {
+	if method == http.MethodGet || method == http.MethodDelete {
 		if encoded := values.Encode(); encoded != "" {
 			endpoint = endpoint + "?" + encoded
 		}
@@ -75,7 +106,7 @@ func (c *Client) do(ctx context.Context, method, path string, params map[string]
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
-	if method != http.MethodGet {
+	if body != nil {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
 
@@ -101,9 +132,9 @@ func (c *Client) do(ctx context.Context, method, path string, params map[string]
 		return nil, fmt.Errorf("%w: status %d", ErrGraphAPIStatus, resp.StatusCode)
 	}
 
-	var parsed JSON
-	if err := json.Unmarshal(payload, &parsed); err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+	parsed, err := parseJSON(payload)
+	if err != nil {
+		return nil, err
 	}
 
 	if apiErr := apiErrorFromJSON(parsed); apiErr != nil {
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Collaborator struct {
+	ID           string
+	Username     string
+	InviteStatus string
+}
+
+// FetchCollaborators lists the collaborator invites on published media and
+// their status (accepted, pending or declined).
+func (c *Client) FetchCollaborators(ctx context.Context, mediaID string) ([]Collaborator, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("%s/collaborators", mediaID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCollaborators(resp)
+}
+
+type collaboratorsResponse struct {
+	Data []struct {
+		ID           string `json:"id"`
+		Username     string `json:"username"`
+		InviteStatus string `json:"invite_status"`
+	} `json:"data"`
+}
+
+func parseCollaborators(payload JSON) ([]Collaborator, error) {
+	var parsed collaboratorsResponse
+	if err := decodeJSON(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("parse collaborators: %w", err)
+	}
+
+	collaborators := make([]Collaborator, 0, len(parsed.Data))
+	for _, entry := range parsed.Data {
+		collaborators = append(collaborators, Collaborator{
+			ID:           entry.ID,
+			Username:     entry.Username,
+			InviteStatus: strings.ToLower(entry.InviteStatus),
+		})
+	}
+
+	return collaborators, nil
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchCollaborators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v19.0/777/collaborators" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"1","username":"creator","invite_status":"Accepted"},
+			{"id":"2","username":"studio","invite_status":"Pending"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	collaborators, err := client.FetchCollaborators(context.Background(), "777")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(collaborators) != 2 {
+		t.Fatalf("expected 2 collaborators, got %d", len(collaborators))
+	}
+
+	if collaborators[0].Username != "creator" || collaborators[0].InviteStatus != "accepted" {
+		t.Fatalf("unexpected collaborator: %#v", collaborators[0])
+	}
+
+	if collaborators[1].InviteStatus != "pending" {
+		t.Fatalf("unexpected status: %s", collaborators[1].InviteStatus)
+	}
+}
@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// CreateComment posts a comment on published media as the Instagram account
+// and returns the comment ID.
+func (c *Client) CreateComment(ctx context.Context, mediaID, message string) (string, error) {
+	resp, err := c.post(ctx, fmt.Sprintf("%s/comments", mediaID), map[string]string{
+		"message": message,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return extractID(resp)
+}
@@ -0,0 +1,37 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("expected POST, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/v19.0/777/comments" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "message", "#one #two")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"c1"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	id, err := client.CreateComment(context.Background(), "777", "#one #two")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "c1" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
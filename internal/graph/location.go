@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Location struct {
+	ID        string
+	Name      string
+	Street    string
+	City      string
+	State     string
+	Country   string
+	Zip       string
+	Latitude  float64
+	Longitude float64
+}
+
+// Address joins the non-empty address parts of the location.
+func (l Location) Address() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{l.Street, l.City, l.State, l.Zip, l.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// SearchLocations looks up Facebook Pages with a physical location matching
+// query. The returned IDs can be used as location_id on media containers.
+func (c *Client) SearchLocations(ctx context.Context, query string) ([]Location, error) {
+	resp, err := c.get(ctx, "pages/search", map[string]string{
+		"q":      query,
+		"fields": "id,name,location",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLocations(resp)
+}
+
+type locationsResponse struct {
+	Data []struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Location *struct {
+			Street    string  `json:"street"`
+			City      string  `json:"city"`
+			State     string  `json:"state"`
+			Country   string  `json:"country"`
+			Zip       string  `json:"zip"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"location"`
+	} `json:"data"`
+}
+
+func parseLocations(payload JSON) ([]Location, error) {
+	var parsed locationsResponse
+	if err := decodeJSON(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("parse locations: %w", err)
+	}
+
+	locations := make([]Location, 0, len(parsed.Data))
+	for _, entry := range parsed.Data {
+		// Pages without a location cannot be used to tag media.
+		if entry.Location == nil {
+			continue
+		}
+
+		locations = append(locations, Location{
+			ID:        entry.ID,
+			Name:      entry.Name,
+			Street:    entry.Location.Street,
+			City:      entry.Location.City,
+			State:     entry.Location.State,
+			Country:   entry.Location.Country,
+			Zip:       entry.Location.Zip,
+			Latitude:  entry.Location.Latitude,
+			Longitude: entry.Location.Longitude,
+		})
+	}
+
+	return locations, nil
+}
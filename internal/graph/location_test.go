@@ -0,0 +1,48 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchLocations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("expected GET, got %s", r.Method)
+		}
+
+		if r.URL.Path != "/v19.0/pages/search" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		assertFormValue(t, r.URL.Query(), "q", "blue bottle")
+		assertFormValue(t, r.URL.Query(), "fields", "id,name,location")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"1","name":"Blue Bottle","location":{"street":"1 Main St","city":"Oakland","country":"United States","latitude":37.8,"longitude":-122.27}},
+			{"id":"2","name":"Blue Bottle Fans"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	locations, err := client.SearchLocations(context.Background(), "blue bottle")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(locations) != 1 {
+		t.Fatalf("expected 1 location, got %d", len(locations))
+	}
+
+	if locations[0].ID != "1" || locations[0].Latitude != 37.8 {
+		t.Fatalf("unexpected location: %#v", locations[0])
+	}
+
+	if got := locations[0].Address(); got != "1 Main St, Oakland, United States" {
+		t.Fatalf("unexpected address: %s", got)
+	}
+}
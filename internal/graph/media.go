@@ -2,12 +2,140 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func (c *Client) CreatePhotoContainer(ctx context.Context, imageURL, caption string) (string, error) {
+// UserTag tags an Instagram account at a position on an image. X and Y are
+// fractions of the image width and height, from the top-left corner.
+type UserTag struct {
+	Username string  `json:"username"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+}
+
+// ProductTag tags a catalog product at a position on an image, using the
+// same coordinate system as UserTag.
+type ProductTag struct {
+	ProductID string  `json:"product_id"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+}
+
+// MediaOptions holds optional container parameters shared by media types.
+type MediaOptions struct {
+	UserTags      []UserTag
+	LocationID    string
+	Collaborators []string
+	ProductTags   []ProductTag
+	AltText       string
+
+	// UploadFile, for video containers, is a local file sent straight to
+	// Meta with a resumable upload. The media URL is ignored when it is set.
+	UploadFile string
+}
+
+func (o MediaOptions) apply(params map[string]string) error {
+	if o.LocationID != "" {
+		params["location_id"] = o.LocationID
+	}
+
+	if o.AltText != "" {
+		params["alt_text"] = o.AltText
+	}
+
+	if len(o.UserTags) > 0 {
+		raw, err := json.Marshal(o.UserTags)
+		if err != nil {
+			return fmt.Errorf("encode user tags: %w", err)
+		}
+
+		params["user_tags"] = string(raw)
+	}
+
+	if len(o.Collaborators) > 0 {
+		raw, err := json.Marshal(o.Collaborators)
+		if err != nil {
+			return fmt.Errorf("encode collaborators: %w", err)
+		}
+
+		params["collaborators"] = string(raw)
+	}
+
+	if len(o.ProductTags) > 0 {
+		raw, err := json.Marshal(o.ProductTags)
+		if err != nil {
+			return fmt.Errorf("encode product tags: %w", err)
+		}
+
+		params["product_tags"] = string(raw)
+	}
+
+	return nil
+}
+
+// ReelOptions holds optional reel container parameters.
+type ReelOptions struct {
+	MediaOptions
+
+	CoverURL    string
+	ThumbOffset *int // milliseconds into the video
+	ShareToFeed *bool
+	AudioName   string
+
+	// TrialGraduation publishes the reel as a trial reel, shown to
+	// non-followers first. See TrialGraduationManual and
+	// TrialGraduationPerformance.
+	TrialGraduation string
+}
+
+// Trial reel graduation strategies.
+const (
+	TrialGraduationManual      = "MANUAL"
+	TrialGraduationPerformance = "SS_PERFORMANCE"
+)
+
+type trialParams struct {
+	GraduationStrategy string `json:"graduation_strategy"`
+}
+
+func (o ReelOptions) apply(params map[string]string) error {
+	if err := o.MediaOptions.apply(params); err != nil {
+		return err
+	}
+
+	if o.CoverURL != "" {
+		params["cover_url"] = o.CoverURL
+	}
+
+	if o.ThumbOffset != nil {
+		params["thumb_offset"] = strconv.Itoa(*o.ThumbOffset)
+	}
+
+	if o.ShareToFeed != nil {
+		params["share_to_feed"] = strconv.FormatBool(*o.ShareToFeed)
+	}
+
+	if o.AudioName != "" {
+		params["audio_name"] = o.AudioName
+	}
+
+	if o.TrialGraduation != "" {
+		raw, err := json.Marshal(trialParams{GraduationStrategy: o.TrialGraduation})
+		if err != nil {
+			return fmt.Errorf("encode trial params: %w", err)
+		}
+
+		params["trial_params"] = string(raw)
+	}
+
+	return nil
+}
+
+func (c *Client) CreatePhotoContainer(ctx context.Context, imageURL, caption string, opts MediaOptions) (string, error) {
 	params := map[string]string{
 		"image_url": imageURL,
 	}
@@ -15,50 +143,76 @@ func (c *Client) CreatePhotoContainer(ctx context.Context, imageURL, caption str
 		params["caption"] = caption
 	}
 
-	resp, err := c.post(ctx, fmt.Sprintf("%s/media", c.igUserID), params)
-	if err != nil {
+	if err := opts.apply(params); err != nil {
 		return "", err
 	}
 
-	return extractID(resp)
+	return c.createContainer(ctx, params, "")
 }
 
-func (c *Client) CreateReelContainer(ctx context.Context, videoURL, caption string) (string, error) {
+func (c *Client) CreateReelContainer(ctx context.Context, videoURL, caption string, opts ReelOptions) (string, error) {
 	params := map[string]string{
 		"media_type": "REELS",
-		"video_url":  videoURL,
+	}
+	if opts.UploadFile == "" {
+		params["video_url"] = videoURL
 	}
 	if caption != "" {
 		params["caption"] = caption
 	}
 
-	resp, err := c.post(ctx, fmt.Sprintf("%s/media", c.igUserID), params)
-	if err != nil {
+	if err := opts.apply(params); err != nil {
 		return "", err
 	}
 
-	return extractID(resp)
+	return c.createContainer(ctx, params, opts.UploadFile)
+}
+
+func (c *Client) CreateStoryContainer(ctx context.Context, mediaURL string, isVideo bool, opts MediaOptions) (string, error) {
+	params := map[string]string{
+		"media_type": "STORIES",
+	}
+	uploadFile := ""
+	switch {
+	case isVideo && opts.UploadFile != "":
+		uploadFile = opts.UploadFile
+	case isVideo:
+		params["video_url"] = mediaURL
+	default:
+		params["image_url"] = mediaURL
+	}
+
+	if err := opts.apply(params); err != nil {
+		return "", err
+	}
+
+	return c.createContainer(ctx, params, uploadFile)
 }
 
-func (c *Client) CreateCarouselChild(ctx context.Context, mediaURL string, isVideo bool) (string, error) {
+func (c *Client) CreateCarouselChild(ctx context.Context, mediaURL string, isVideo bool, opts MediaOptions) (string, error) {
 	params := map[string]string{
 		"is_carousel_item": "true",
 	}
-	if isVideo {
+	uploadFile := ""
+	switch {
+	case isVideo && opts.UploadFile != "":
+		// Resumable containers need an explicit media type.
+		params["media_type"] = "VIDEO"
+		uploadFile = opts.UploadFile
+	case isVideo:
 		params["video_url"] = mediaURL
-	} else {
+	default:
 		params["image_url"] = mediaURL
 	}
 
-	resp, err := c.post(ctx, fmt.Sprintf("%s/media", c.igUserID), params)
-	if err != nil {
+	if err := opts.apply(params); err != nil {
 		return "", err
 	}
 
-	return extractID(resp)
+	return c.createContainer(ctx, params, uploadFile)
 }
 
-func (c *Client) CreateCarouselContainer(ctx context.Context, childIDs []string, caption string) (string, error) {
+func (c *Client) CreateCarouselContainer(ctx context.Context, childIDs []string, caption string, opts MediaOptions) (string, error) {
 	params := map[string]string{
 		"media_type": "CAROUSEL",
 		"children":   strings.Join(childIDs, ","),
@@ -67,12 +221,11 @@ func (c *Client) CreateCarouselContainer(ctx context.Context, childIDs []string,
 		params["caption"] = caption
 	}
 
-	resp, err := c.post(ctx, fmt.Sprintf("%s/media", c.igUserID), params)
-	if err != nil {
+	if err := opts.apply(params); err != nil {
 		return "", err
 	}
 
-	return extractID(resp)
+	return c.createContainer(ctx, params, "")
 }
 
 func (c *Client) PollStatus(ctx context.Context, creationID string, interval, timeout time.Duration) error {
@@ -129,6 +282,8 @@ func extractID(resp JSON) (string, error) {
 		}
 
 		return typed, nil
+	case json.Number:
+		return typed.String(), nil
 	case float64:
 		return fmt.Sprintf("%.0f", typed), nil
 	default:
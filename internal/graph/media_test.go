@@ -32,7 +32,7 @@ func TestCreatePhotoContainer(t *testing.T) {
 
 	client := newTestClient(server)
 
-	id, err := client.CreatePhotoContainer(context.Background(), "https://example.com/photo.jpg", "hello")
+	id, err := client.CreatePhotoContainer(context.Background(), "https://example.com/photo.jpg", "hello", MediaOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -42,6 +42,36 @@ func TestCreatePhotoContainer(t *testing.T) {
 	}
 }
 
+func TestCreatePhotoContainerOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "image_url", "https://example.com/photo.jpg")
+		assertFormValue(t, r.Form, "user_tags", `[{"username":"partner","x":0.5,"y":0.8}]`)
+		assertFormValue(t, r.Form, "collaborators", `["creator","studio"]`)
+		assertFormValue(t, r.Form, "alt_text", "A blue mug on a desk")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"112"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	opts := MediaOptions{
+		UserTags:      []UserTag{{Username: "partner", X: 0.5, Y: 0.8}},
+		Collaborators: []string{"creator", "studio"},
+		AltText:       "A blue mug on a desk",
+	}
+
+	id, err := client.CreatePhotoContainer(context.Background(), "https://example.com/photo.jpg", "", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "112" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
 func TestCreateReelContainer(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = r.ParseForm()
@@ -54,7 +84,7 @@ func TestCreateReelContainer(t *testing.T) {
 
 	client := newTestClient(server)
 
-	id, err := client.CreateReelContainer(context.Background(), "https://example.com/reel.mp4", "")
+	id, err := client.CreateReelContainer(context.Background(), "https://example.com/reel.mp4", "", ReelOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -64,6 +94,66 @@ func TestCreateReelContainer(t *testing.T) {
 	}
 }
 
+func TestCreateReelContainerOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "cover_url", "https://example.com/cover.jpg")
+		assertFormValue(t, r.Form, "thumb_offset", "0")
+		assertFormValue(t, r.Form, "share_to_feed", "false")
+		assertFormValue(t, r.Form, "audio_name", "Original audio")
+		assertFormValue(t, r.Form, "location_id", "7890")
+		assertFormValue(t, r.Form, "trial_params", `{"graduation_strategy":"SS_PERFORMANCE"}`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"223"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	offset := 0
+	share := false
+	opts := ReelOptions{
+		MediaOptions:    MediaOptions{LocationID: "7890"},
+		CoverURL:        "https://example.com/cover.jpg",
+		ThumbOffset:     &offset,
+		ShareToFeed:     &share,
+		AudioName:       "Original audio",
+		TrialGraduation: TrialGraduationPerformance,
+	}
+
+	id, err := client.CreateReelContainer(context.Background(), "https://example.com/reel.mp4", "", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "223" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
+func TestCreateStoryContainer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "media_type", "STORIES")
+		assertFormValue(t, r.Form, "image_url", "https://example.com/story.jpg")
+		assertFormValue(t, r.Form, "video_url", "")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"666"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	id, err := client.CreateStoryContainer(context.Background(), "https://example.com/story.jpg", false, MediaOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "666" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
 func TestCreateCarouselChild(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = r.ParseForm()
@@ -76,7 +166,7 @@ func TestCreateCarouselChild(t *testing.T) {
 
 	client := newTestClient(server)
 
-	id, err := client.CreateCarouselChild(context.Background(), "https://example.com/clip.mp4", true)
+	id, err := client.CreateCarouselChild(context.Background(), "https://example.com/clip.mp4", true, MediaOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -86,11 +176,36 @@ func TestCreateCarouselChild(t *testing.T) {
 	}
 }
 
+func TestCreateCarouselChildProductTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "image_url", "https://example.com/item.jpg")
+		assertFormValue(t, r.Form, "product_tags", `[{"product_id":"3231775643511089","x":0.25,"y":0.75}]`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"334"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	opts := MediaOptions{ProductTags: []ProductTag{{ProductID: "3231775643511089", X: 0.25, Y: 0.75}}}
+
+	id, err := client.CreateCarouselChild(context.Background(), "https://example.com/item.jpg", false, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "334" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
 func TestCreateCarouselContainer(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = r.ParseForm()
 		assertFormValue(t, r.Form, "media_type", "CAROUSEL")
 		assertFormValue(t, r.Form, "children", "1,2,3")
+		assertFormValue(t, r.Form, "location_id", "7890")
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write([]byte(`{"id":"444"}`))
 	}))
@@ -99,7 +214,7 @@ func TestCreateCarouselContainer(t *testing.T) {
 
 	client := newTestClient(server)
 
-	id, err := client.CreateCarouselContainer(context.Background(), []string{"1", "2", "3"}, "caption")
+	id, err := client.CreateCarouselContainer(context.Background(), []string{"1", "2", "3"}, "caption", MediaOptions{LocationID: "7890"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
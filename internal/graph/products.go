@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type CatalogProduct struct {
+	ProductID    string
+	Name         string
+	RetailerID   string
+	ReviewStatus string
+	ImageURL     string
+}
+
+// SearchCatalogProducts finds taggable products in a catalog owned by the
+// Instagram account's shop.
+func (c *Client) SearchCatalogProducts(ctx context.Context, catalogID, query string) ([]CatalogProduct, error) {
+	params := map[string]string{
+		"catalog_id": catalogID,
+	}
+	if query != "" {
+		params["q"] = query
+	}
+
+	resp, err := c.get(ctx, fmt.Sprintf("%s/catalog_product_search", c.igUserID), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Data []struct {
+			ProductID    json.Number `json:"product_id"`
+			ProductName  string      `json:"product_name"`
+			RetailerID   string      `json:"retailer_id"`
+			ReviewStatus string      `json:"review_status"`
+			ImageURL     string      `json:"image_url"`
+		} `json:"data"`
+	}
+	if err := decodeJSON(resp, &parsed); err != nil {
+		return nil, fmt.Errorf("parse catalog products: %w", err)
+	}
+
+	products := make([]CatalogProduct, 0, len(parsed.Data))
+	for _, entry := range parsed.Data {
+		products = append(products, CatalogProduct{
+			ProductID:    entry.ProductID.String(),
+			Name:         entry.ProductName,
+			RetailerID:   entry.RetailerID,
+			ReviewStatus: entry.ReviewStatus,
+			ImageURL:     entry.ImageURL,
+		})
+	}
+
+	return products, nil
+}
+
+type MediaProductTag struct {
+	ProductTag
+
+	Name         string
+	ReviewStatus string
+}
+
+// FetchProductTags lists the product tags on published media.
+func (c *Client) FetchProductTags(ctx context.Context, mediaID string) ([]MediaProductTag, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("%s/product_tags", mediaID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Data []struct {
+			ProductID    json.Number `json:"product_id"`
+			Name         string      `json:"name"`
+			ReviewStatus string      `json:"review_status"`
+			X            float64     `json:"x"`
+			Y            float64     `json:"y"`
+		} `json:"data"`
+	}
+	if err := decodeJSON(resp, &parsed); err != nil {
+		return nil, fmt.Errorf("parse product tags: %w", err)
+	}
+
+	tags := make([]MediaProductTag, 0, len(parsed.Data))
+	for _, entry := range parsed.Data {
+		tags = append(tags, MediaProductTag{
+			ProductTag:   ProductTag{ProductID: entry.ProductID.String(), X: entry.X, Y: entry.Y},
+			Name:         entry.Name,
+			ReviewStatus: entry.ReviewStatus,
+		})
+	}
+
+	return tags, nil
+}
+
+// UpdateProductTags adds tags to published media, or moves existing tags for
+// the same products.
+func (c *Client) UpdateProductTags(ctx context.Context, mediaID string, tags []ProductTag) error {
+	raw, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("encode product tags: %w", err)
+	}
+
+	resp, err := c.post(ctx, fmt.Sprintf("%s/product_tags", mediaID), map[string]string{
+		"updated_tags": string(raw),
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkSuccess(resp)
+}
+
+// DeleteProductTags removes the tags for the given products from published media.
+func (c *Client) DeleteProductTags(ctx context.Context, mediaID string, productIDs []string) error {
+	type deletedTag struct {
+		ProductID string `json:"product_id"`
+	}
+
+	deleted := make([]deletedTag, 0, len(productIDs))
+	for _, id := range productIDs {
+		deleted = append(deleted, deletedTag{ProductID: id})
+	}
+
+	raw, err := json.Marshal(deleted)
+	if err != nil {
+		return fmt.Errorf("encode product tags: %w", err)
+	}
+
+	resp, err := c.delete(ctx, fmt.Sprintf("%s/product_tags", mediaID), map[string]string{
+		"deleted_tags": string(raw),
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkSuccess(resp)
+}
+
+func checkSuccess(resp JSON) error {
+	if ok, _ := resp["success"].(bool); !ok {
+		return ErrRequestUnsuccessful
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchCatalogProducts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v19.0/123/catalog_product_search" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		assertFormValue(t, r.URL.Query(), "catalog_id", "cat1")
+		assertFormValue(t, r.URL.Query(), "q", "mug")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"product_id":1234567890123456789,"product_name":"Blue Mug","retailer_id":"SKU-1","review_status":"approved"}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	products, err := client.SearchCatalogProducts(context.Background(), "cat1", "mug")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(products) != 1 {
+		t.Fatalf("expected 1 product, got %d", len(products))
+	}
+
+	if products[0].ProductID != "1234567890123456789" || products[0].Name != "Blue Mug" {
+		t.Fatalf("unexpected product: %#v", products[0])
+	}
+}
+
+func TestFetchProductTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v19.0/777/product_tags" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"product_id":9007199254740993123,"name":"Blue Mug","review_status":"approved","x":0.5,"y":0.25}]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	tags, err := client.FetchProductTags(context.Background(), "777")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tags) != 1 || tags[0].ProductID != "9007199254740993123" || tags[0].X != 0.5 || tags[0].Y != 0.25 {
+		t.Fatalf("unexpected tags: %#v", tags)
+	}
+}
+
+func TestUpdateProductTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("expected POST, got %s", r.Method)
+		}
+
+		_ = r.ParseForm()
+		assertFormValue(t, r.Form, "updated_tags", `[{"product_id":"42","x":0.1,"y":0.2}]`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	err := client.UpdateProductTags(context.Background(), "777", []ProductTag{{ProductID: "42", X: 0.1, Y: 0.2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteProductTagsUnsuccessful(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Fatalf("expected DELETE, got %s", r.Method)
+		}
+
+		assertFormValue(t, r.URL.Query(), "deleted_tags", `[{"product_id":"42"}]`)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":false}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	err := client.DeleteProductTags(context.Background(), "777", []string{"42"})
+	if !errors.Is(err, ErrRequestUnsuccessful) {
+		t.Fatalf("expected ErrRequestUnsuccessful, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahmoudashraf93/poster/internal/progress"
+)
+
+const (
+	defaultRuploadURL = "https://rupload.facebook.com/ig-api-upload/"
+	resumableAttempts = 5
+)
+
+// resumableRetryDelay is the wait before the first resume; it grows
+// linearly with each attempt. Tests shorten it.
+var resumableRetryDelay = 2 * time.Second
+
+// createContainer creates a media container from params. When uploadFile is
+// set, the container is created with upload_type=resumable and the file is
+// sent straight to Meta's rupload host instead of being fetched from a URL.
+func (c *Client) createContainer(ctx context.Context, params map[string]string, uploadFile string) (string, error) {
+	if uploadFile != "" {
+		params["upload_type"] = "resumable"
+	}
+
+	resp, err := c.post(ctx, fmt.Sprintf("%s/media", c.igUserID), params)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := extractID(resp)
+	if err != nil || uploadFile == "" {
+		return id, err
+	}
+
+	uri, _ := resp["uri"].(string)
+	if uri == "" {
+		uri = c.ruploadEndpoint(id)
+	}
+
+	if err = c.uploadVideo(ctx, id, uri, uploadFile); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (c *Client) ruploadEndpoint(id string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(c.ruploadURL, "/"), c.graphVersion, id)
+}
+
+// uploadVideo sends path to the rupload uri. After a network error or a 5xx
+// response it asks Graph how many bytes arrived and resumes from there.
+func (c *Client) uploadVideo(ctx context.Context, id, uri, path string) error {
+	// #nosec G304 -- path is user-provided
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open video: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat video: %w", err)
+	}
+
+	size := info.Size()
+	var offset int64
+	for attempt := 1; ; attempt++ {
+		err = c.sendVideo(ctx, uri, file, offset, size)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= resumableAttempts || ctx.Err() != nil || !resumable(err) {
+			return err
+		}
+
+		wait := time.Duration(attempt) * resumableRetryDelay
+		slog.Warn("video upload interrupted, resuming", "container", id, "attempt", attempt, "wait", wait, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("video upload canceled: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+
+		transferred, offsetErr := c.uploadedBytes(ctx, id)
+		if offsetErr != nil {
+			slog.Warn("could not read upload offset, restarting", "container", id, "err", offsetErr)
+			transferred = 0
+		}
+
+		offset = min(max(transferred, 0), size)
+	}
+}
+
+type ruploadResponse struct {
+	Success   bool `json:"success"`
+	DebugInfo struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"debug_info"`
+}
+
+// ruploadStatusError is a non-2xx answer from the rupload host.
+type ruploadStatusError struct {
+	status  int
+	message string
+}
+
+func (e *ruploadStatusError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("%s: status %d", ErrVideoUpload, e.status)
+	}
+
+	return fmt.Sprintf("%s: status %d: %s", ErrVideoUpload, e.status, e.message)
+}
+
+func (e *ruploadStatusError) Unwrap() error {
+	return ErrVideoUpload
+}
+
+func (c *Client) sendVideo(ctx context.Context, uri string, file *os.File, offset, size int64) error {
+	body := progress.Track(ctx, io.NewSectionReader(file, offset, size-offset), size-offset)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.ContentLength = size - offset
+	req.Header.Set("Authorization", "OAuth "+c.accessToken)
+	req.Header.Set("offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("file_size", strconv.FormatInt(size, 10))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("video upload request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	var parsed ruploadResponse
+	_ = json.Unmarshal(payload, &parsed)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &ruploadStatusError{status: resp.StatusCode, message: parsed.DebugInfo.Message}
+	}
+
+	if !parsed.Success {
+		return fmt.Errorf("%w: %s", ErrVideoUpload, strings.TrimSpace(string(payload)))
+	}
+
+	return nil
+}
+
+// uploadedBytes asks Graph how much of the video reached Meta.
+func (c *Client) uploadedBytes(ctx context.Context, id string) (int64, error) {
+	resp, err := c.get(ctx, id, map[string]string{"fields": "video_status"})
+	if err != nil {
+		return 0, err
+	}
+
+	var status struct {
+		VideoStatus struct {
+			UploadingPhase struct {
+				BytesTransferred json.Number `json:"bytes_transferred"`
+			} `json:"uploading_phase"`
+		} `json:"video_status"`
+	}
+
+	if err = decodeJSON(resp, &status); err != nil {
+		return 0, err
+	}
+
+	raw := status.VideoStatus.UploadingPhase.BytesTransferred
+	if raw == "" {
+		return 0, nil
+	}
+
+	return raw.Int64()
+}
+
+func resumable(err error) bool {
+	var statusErr *ruploadStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status >= http.StatusInternalServerError || statusErr.status == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestVideo(t *testing.T, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	return path
+}
+
+func TestCreateReelContainerResumableUpload(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	var received []byte
+	uploads := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v19.0/123/media":
+			_ = r.ParseForm()
+			assertFormValue(t, r.Form, "media_type", "REELS")
+			assertFormValue(t, r.Form, "upload_type", "resumable")
+			assertFormValue(t, r.Form, "caption", "hello")
+			if r.Form.Has("video_url") {
+				t.Fatalf("unexpected video_url: %s", r.Form.Get("video_url"))
+			}
+			_, _ = w.Write([]byte(`{"id":"777","uri":"` + server.URL + `/rupload/v19.0/777"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rupload/v19.0/777":
+			uploads++
+			if r.Header.Get("Authorization") != "OAuth token" {
+				t.Fatalf("unexpected authorization: %q", r.Header.Get("Authorization"))
+			}
+			if r.Header.Get("File_size") != "16" {
+				t.Fatalf("unexpected file_size: %q", r.Header.Get("File_size"))
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			if uploads == 1 {
+				if r.Header.Get("Offset") != "0" {
+					t.Fatalf("unexpected first offset: %q", r.Header.Get("Offset"))
+				}
+				// Pretend the connection dropped after 6 bytes.
+				received = append(received, body[:6]...)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"debug_info":{"type":"ProcessingFailedError","message":"try again"}}`))
+				return
+			}
+
+			if r.Header.Get("Offset") != "6" {
+				t.Fatalf("expected resume from offset 6, got %q", r.Header.Get("Offset"))
+			}
+			received = append(received, body...)
+			_, _ = w.Write([]byte(`{"success":true,"message":"Upload successful."}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v19.0/777":
+			assertFormValue(t, r.URL.Query(), "fields", "video_status")
+			_, _ = w.Write([]byte(`{"id":"777","video_status":{"uploading_phase":{"status":"in_progress","bytes_transferred":6}}}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	restore := resumableRetryDelay
+	resumableRetryDelay = 0
+	defer func() { resumableRetryDelay = restore }()
+
+	client := newTestClient(server)
+
+	opts := ReelOptions{MediaOptions: MediaOptions{UploadFile: writeTestVideo(t, content)}}
+	id, err := client.CreateReelContainer(context.Background(), "", "hello", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "777" || uploads != 2 {
+		t.Fatalf("unexpected result: id %s after %d uploads", id, uploads)
+	}
+
+	if !bytes.Equal(received, content) {
+		t.Fatalf("unexpected bytes received: %q", received)
+	}
+}
+
+func TestCreateCarouselChildResumableUsesDefaultEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/v19.0/123/media":
+			_ = r.ParseForm()
+			assertFormValue(t, r.Form, "is_carousel_item", "true")
+			assertFormValue(t, r.Form, "media_type", "VIDEO")
+			assertFormValue(t, r.Form, "upload_type", "resumable")
+			_, _ = w.Write([]byte(`{"id":"778"}`))
+		case "/rupload/v19.0/778":
+			_, _ = w.Write([]byte(`{"success":true}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	client.ruploadURL = server.URL + "/rupload/"
+
+	id, err := client.CreateCarouselChild(context.Background(), "", true, MediaOptions{UploadFile: writeTestVideo(t, []byte("video"))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "778" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
+func TestResumableUploadStopsOnClientError(t *testing.T) {
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/v19.0/123/media" {
+			_, _ = w.Write([]byte(`{"id":"779"}`))
+			return
+		}
+
+		uploads++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"debug_info":{"type":"BadRequest","message":"invalid file"}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	client.ruploadURL = server.URL + "/rupload/"
+
+	_, err := client.CreateStoryContainer(context.Background(), "", true, MediaOptions{UploadFile: writeTestVideo(t, []byte("video"))})
+	if !errors.Is(err, ErrVideoUpload) {
+		t.Fatalf("expected ErrVideoUpload, got %v", err)
+	}
+
+	if uploads != 1 {
+		t.Fatalf("expected no retries for a client error, got %d uploads", uploads)
+	}
+}
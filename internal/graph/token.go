@@ -216,9 +216,9 @@ func graphGet(ctx context.Context, path string, params map[string]string) (JSON,
 		return nil, fmt.Errorf("%w: status %d", ErrGraphAPIStatus, resp.StatusCode)
 	}
 
-	var parsed JSON
-	if err := json.Unmarshal(payload, &parsed); err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+	parsed, err := parseJSON(payload)
+	if err != nil {
+		return nil, err
 	}
 
 	if apiErr := apiErrorFromJSON(parsed); apiErr != nil {
@@ -256,9 +256,9 @@ func graphGetURL(ctx context.Context, endpoint string) (JSON, error) {
 		return nil, fmt.Errorf("%w: status %d", ErrGraphAPIStatus, resp.StatusCode)
 	}
 
-	var parsed JSON
-	if err := json.Unmarshal(payload, &parsed); err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+	parsed, err := parseJSON(payload)
+	if err != nil {
+		return nil, err
 	}
 
 	if apiErr := apiErrorFromJSON(parsed); apiErr != nil {
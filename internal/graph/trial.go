@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+type TrialStatus struct {
+	MediaProductType   string
+	IsTrial            bool
+	GraduationStrategy string
+}
+
+// FetchTrialStatus reports whether a reel is (still) a trial reel and how it
+// is set to graduate. Graduated reels no longer carry trial params.
+func (c *Client) FetchTrialStatus(ctx context.Context, mediaID string) (TrialStatus, error) {
+	resp, err := c.get(ctx, mediaID, map[string]string{
+		"fields": "media_product_type,trial_params",
+	})
+	if err != nil {
+		return TrialStatus{}, err
+	}
+
+	var parsed struct {
+		MediaProductType string       `json:"media_product_type"`
+		TrialParams      *trialParams `json:"trial_params"`
+	}
+	if err := decodeJSON(resp, &parsed); err != nil {
+		return TrialStatus{}, fmt.Errorf("parse trial status: %w", err)
+	}
+
+	status := TrialStatus{MediaProductType: parsed.MediaProductType}
+	if parsed.TrialParams != nil {
+		status.IsTrial = true
+		status.GraduationStrategy = parsed.TrialParams.GraduationStrategy
+	}
+
+	return status, nil
+}
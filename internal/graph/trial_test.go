@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchTrialStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v19.0/888" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		assertFormValue(t, r.URL.Query(), "fields", "media_product_type,trial_params")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"888","media_product_type":"REELS","trial_params":{"graduation_strategy":"MANUAL"}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+
+	status, err := client.FetchTrialStatus(context.Background(), "888")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !status.IsTrial || status.GraduationStrategy != TrialGraduationManual {
+		t.Fatalf("unexpected status: %#v", status)
+	}
+}
@@ -0,0 +1,204 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Instagram's maximum image width and the JPEG quality range tried when
+// converting.
+const (
+	MaxImageWidth     = 1440
+	maxConvertQuality = 92
+	minConvertQuality = 40
+)
+
+// ConvertOptions controls ToJPEG. Zero fields fall back to defaults.
+type ConvertOptions struct {
+	// Background is what transparent pixels are flattened onto. Default
+	// white.
+	Background color.Color
+	// MaxWidth is the width larger images are scaled down to. Default
+	// MaxImageWidth.
+	MaxWidth int
+	// MaxBytes is the size the JPEG must fit in. Default MaxImageBytes.
+	MaxBytes int64
+	// Dir is where converted files are written. Default os.TempDir().
+	Dir string
+	// Fit brings the image into an aspect ratio range before it is scaled.
+	Fit FitOptions
+	// KeepCopyright carries the EXIF artist and copyright over; all other
+	// metadata is dropped.
+	KeepCopyright bool
+}
+
+func (o ConvertOptions) withDefaults() ConvertOptions {
+	if o.Background == nil {
+		o.Background = color.White
+	}
+
+	if o.MaxWidth <= 0 {
+		o.MaxWidth = MaxImageWidth
+	}
+
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = MaxImageBytes
+	}
+
+	return o
+}
+
+// NeedsConversion reports whether img has to be converted before Instagram
+// accepts it: it is not a JPEG, or it is a JPEG over the size limit.
+func NeedsConversion(img Image) bool {
+	if img.Container == ContainerHEIC {
+		return false
+	}
+
+	return img.Container != ContainerJPEG || img.Size > MaxImageBytes
+}
+
+// ToJPEG converts the image at path to a JPEG in opts.Dir and returns the new
+// path. The EXIF orientation is applied to the pixels, the image is cropped
+// or padded as opts.Fit asks, transparency is flattened onto the background,
+// images wider than MaxWidth are scaled down, and the highest quality that
+// fits in MaxBytes is used. Animated GIFs keep their first frame. Metadata is
+// not carried over, except the copyright with KeepCopyright.
+func ToJPEG(path string, opts ConvertOptions) (string, error) {
+	opts = opts.withDefaults()
+
+	// #nosec G304 -- path is user-provided
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	meta, err := readMetadata(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	var keep []byte
+	if opts.KeepCopyright {
+		keep = exifSegment(0, meta.Artist, meta.Copyright)
+	}
+
+	img := flatten(scaleToWidth(Fit(Orient(src, meta.Orientation), opts.Fit), opts.MaxWidth), opts.Background)
+	encoded, err := encodeWithin(img, opts.MaxBytes-int64(len(keep)))
+	if err != nil {
+		return "", fmt.Errorf("convert %s: %w", path, err)
+	}
+
+	if keep != nil {
+		// The encoder writes no APP segments, so EXIF goes right after SOI.
+		encoded = append(encoded[:2:2], append(keep, encoded[2:]...)...)
+	}
+
+	return writeTemp(opts.Dir, path, encoded)
+}
+
+// writeTemp writes data to a new .jpg file in dir named after path.
+func writeTemp(dir, path string, data []byte) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	out, err := os.CreateTemp(dir, name+"-*.jpg")
+	if err != nil {
+		return "", fmt.Errorf("create converted file: %w", err)
+	}
+
+	if _, err = out.Write(data); err != nil {
+		_ = out.Close()
+		return "", fmt.Errorf("write converted file: %w", err)
+	}
+
+	if err = out.Close(); err != nil {
+		return "", fmt.Errorf("write converted file: %w", err)
+	}
+
+	return out.Name(), nil
+}
+
+// scaleToWidth scales src down so it is at most width pixels wide, keeping
+// the aspect ratio.
+func scaleToWidth(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= width {
+		return src
+	}
+
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// flatten draws src over an opaque background, since JPEG has no alpha.
+func flatten(src image.Image, background color.Color) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// encodeWithin encodes img at the highest quality whose output fits in
+// maxBytes, by binary search over the quality range.
+func encodeWithin(img image.Image, maxBytes int64) ([]byte, error) {
+	var best []byte
+	low, high := minConvertQuality, maxConvertQuality
+	for low <= high {
+		quality := (low + high) / 2
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("encode jpeg: %w", err)
+		}
+
+		if int64(buf.Len()) <= maxBytes {
+			best = buf.Bytes()
+			low = quality + 1
+		} else {
+			high = quality - 1
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("%w: over %d bytes even at quality %d", ErrTooLarge, maxBytes, minConvertQuality)
+	}
+
+	return best, nil
+}
+
+// ParseColor parses a #rgb or #rrggbb hex color.
+func ParseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color %q (expected #rrggbb)", s)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q (expected #rrggbb)", s)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xFF}, nil
+}
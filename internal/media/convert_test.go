@@ -0,0 +1,116 @@
+package media
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeNoisePNG writes a PNG of random colours with the given alpha, so the
+// JPEG size depends on the quality.
+func writeNoisePNG(t *testing.T, width, height int, alpha uint8) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(path) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rng := rand.New(rand.NewPCG(1, 2)) // #nosec G404 -- test data
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = uint8(rng.UintN(256)), uint8(rng.UintN(256)), uint8(rng.UintN(256))
+		img.Pix[i+3] = alpha
+	}
+
+	if err = png.Encode(file, img); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+
+	return path
+}
+
+func TestToJPEGFlattensAndScales(t *testing.T) {
+	dir := t.TempDir()
+	out, err := ToJPEG(writeNoisePNG(t, 2000, 1000, 0), ConvertOptions{
+		Background: color.RGBA{R: 0xFF, A: 0xFF},
+		Dir:        dir,
+	})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	if filepath.Dir(out) != dir || filepath.Ext(out) != ".jpg" {
+		t.Fatalf("unexpected output path: %s", out)
+	}
+
+	img, err := DecodeImage(out)
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+
+	if img.Container != ContainerJPEG || img.Width != MaxImageWidth || img.Height != 720 {
+		t.Fatalf("unexpected output: %#v", img)
+	}
+
+	file, err := os.Open(out) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("open output: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	decoded, _, err := image.Decode(file)
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+
+	r, g, b, _ := decoded.At(10, 10).RGBA()
+	if r>>8 < 0xE0 || g>>8 > 0x20 || b>>8 > 0x20 {
+		t.Fatalf("expected red background, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestToJPEGFitsSizeLimit(t *testing.T) {
+	opaque := writeNoisePNG(t, 400, 400, 0xFF)
+
+	const limit = 60 << 10
+	converted, err := ToJPEG(opaque, ConvertOptions{MaxBytes: limit, Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	stat, err := os.Stat(converted)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if stat.Size() > limit {
+		t.Fatalf("expected at most %d bytes, got %d", limit, stat.Size())
+	}
+
+	if _, err = ToJPEG(opaque, ConvertOptions{MaxBytes: 1 << 10, Dir: t.TempDir()}); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#0a0")
+	if err != nil || c != (color.RGBA{G: 0xAA, A: 0xFF}) {
+		t.Fatalf("unexpected color: %v, %v", c, err)
+	}
+
+	if _, err = ParseColor("white"); err == nil {
+		t.Fatalf("expected error for named color")
+	}
+}
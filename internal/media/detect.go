@@ -0,0 +1,205 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindImage Kind = "image"
+	KindVideo Kind = "video"
+)
+
+// Containers poster recognizes.
+const (
+	ContainerJPEG = "jpeg"
+	ContainerPNG  = "png"
+	ContainerGIF  = "gif"
+	ContainerWebP = "webp"
+	ContainerHEIC = "heic"
+	ContainerMP4  = "mp4"
+	ContainerMOV  = "mov"
+)
+
+// Info describes a media file: whether it is an image or a video, its MIME
+// type and the container format it is stored in.
+type Info struct {
+	Kind      Kind
+	MIMEType  string
+	Container string
+}
+
+func (i Info) IsVideo() bool {
+	return i.Kind == KindVideo
+}
+
+// Supported reports whether Instagram accepts the container.
+func (i Info) Supported() bool {
+	return i.Container != ContainerHEIC
+}
+
+var formats = map[string]Info{
+	ContainerJPEG: {Kind: KindImage, MIMEType: "image/jpeg", Container: ContainerJPEG},
+	ContainerPNG:  {Kind: KindImage, MIMEType: "image/png", Container: ContainerPNG},
+	ContainerGIF:  {Kind: KindImage, MIMEType: "image/gif", Container: ContainerGIF},
+	ContainerWebP: {Kind: KindImage, MIMEType: "image/webp", Container: ContainerWebP},
+	ContainerHEIC: {Kind: KindImage, MIMEType: "image/heic", Container: ContainerHEIC},
+	ContainerMP4:  {Kind: KindVideo, MIMEType: "video/mp4", Container: ContainerMP4},
+	ContainerMOV:  {Kind: KindVideo, MIMEType: "video/quicktime", Container: ContainerMOV},
+}
+
+// ISO base media file format brands, from the ftyp box.
+var (
+	movBrands  = []string{"qt  "}
+	heicBrands = []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "hevm", "hevs", "mif1", "msf1"}
+	mp4Brands  = []string{
+		"isom", "iso2", "iso3", "iso4", "iso5", "iso6", "iso7", "iso8", "iso9",
+		"mp41", "mp42", "avc1", "dash", "M4V ", "M4VH", "M4VP", "mmp4", "MSNV", "XAVC",
+	}
+	// Audio-only files often list mp4 brands as compatible.
+	audioBrands = []string{"M4A ", "M4B ", "M4P "}
+)
+
+// QuickTime files written before ftyp existed start with one of these atoms.
+var movAtoms = []string{"moov", "mdat", "wide", "free", "skip", "pnot"}
+
+// sniffLen is how much of a file Sniff needs to see.
+const sniffLen = 512
+
+// DetectFile identifies the file at p from its content, ignoring the
+// extension.
+func DetectFile(p string) (Info, error) {
+	// #nosec G304 -- p is user-provided
+	file, err := os.Open(p)
+	if err != nil {
+		return Info{}, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	header := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Info{}, fmt.Errorf("read file: %w", err)
+	}
+
+	return Sniff(header[:n])
+}
+
+// Sniff identifies media from the first bytes of a file, using magic numbers
+// and, for ISO base media files (MP4, MOV, HEIC), the ftyp brands.
+func Sniff(header []byte) (Info, error) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		return formats[ContainerJPEG], nil
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return formats[ContainerPNG], nil
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return formats[ContainerGIF], nil
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return formats[ContainerWebP], nil
+	}
+
+	if len(header) >= 8 {
+		boxType := string(header[4:8])
+		if boxType == "ftyp" {
+			return sniffFtyp(header)
+		}
+
+		if slices.Contains(movAtoms, boxType) {
+			return formats[ContainerMOV], nil
+		}
+	}
+
+	return Info{}, ErrUnknownFormat
+}
+
+// sniffFtyp picks the container from the major brand, then from the
+// compatible brands when the major brand is not one poster knows.
+func sniffFtyp(header []byte) (Info, error) {
+	size := int(uint32(header[0])<<24 | uint32(header[1])<<16 | uint32(header[2])<<8 | uint32(header[3]))
+	if size < 16 || size > len(header) {
+		size = len(header)
+	}
+
+	brands := make([]string, 0, (size-8)/4)
+	for offset := 8; offset+4 <= size; offset += 4 {
+		// Bytes 12-16 are the minor version, not a brand.
+		if offset == 12 {
+			continue
+		}
+
+		brands = append(brands, string(header[offset:offset+4]))
+	}
+
+	if len(brands) > 0 && slices.Contains(audioBrands, brands[0]) {
+		return Info{}, fmt.Errorf("%w: audio file (ftyp brand %q)", ErrUnknownFormat, brands[0])
+	}
+
+	for _, brand := range brands {
+		switch {
+		case slices.Contains(movBrands, brand):
+			return formats[ContainerMOV], nil
+		case slices.Contains(heicBrands, brand):
+			return formats[ContainerHEIC], nil
+		case slices.Contains(mp4Brands, brand):
+			return formats[ContainerMP4], nil
+		}
+	}
+
+	return Info{}, fmt.Errorf("%w: ftyp brands %q", ErrUnknownFormat, brands)
+}
+
+// FromExtension identifies media from a file name or URL path. It is only a
+// guess, for remote files poster cannot read.
+func FromExtension(name string) (Info, error) {
+	ext := strings.ToLower(path.Ext(name))
+	switch ext {
+	case ".jpg", ".jpeg":
+		return formats[ContainerJPEG], nil
+	case ".png":
+		return formats[ContainerPNG], nil
+	case ".gif":
+		return formats[ContainerGIF], nil
+	case ".webp":
+		return formats[ContainerWebP], nil
+	case ".heic", ".heif":
+		return formats[ContainerHEIC], nil
+	case ".mp4", ".m4v":
+		return formats[ContainerMP4], nil
+	case ".mov", ".qt":
+		return formats[ContainerMOV], nil
+	default:
+		return Info{}, fmt.Errorf("%w: extension %q", ErrUnknownFormat, ext)
+	}
+}
+
+// FromContentType identifies media from a Content-Type header.
+func FromContentType(contentType string) (Info, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Info{}, fmt.Errorf("%w: content type %q", ErrUnknownFormat, contentType)
+	}
+
+	for _, info := range formats {
+		if info.MIMEType == mediaType {
+			return info, nil
+		}
+	}
+
+	if mediaType == "image/jpg" || mediaType == "image/heif" {
+		return FromExtension("." + strings.TrimPrefix(mediaType, "image/"))
+	}
+
+	return Info{}, fmt.Errorf("%w: content type %q", ErrUnknownFormat, mediaType)
+}
@@ -0,0 +1,100 @@
+package media
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ftyp builds an ftyp box with the given major and compatible brands.
+func ftyp(major string, compatible ...string) []byte {
+	box := []byte{0, 0, 0, 0}
+	box = append(box, "ftyp"+major+"\x00\x00\x02\x00"...)
+	for _, brand := range compatible {
+		box = append(box, brand...)
+	}
+
+	box[3] = byte(len(box))
+	return append(box, "\x00\x00\x00\x08free"...)
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    []byte
+		container string
+		kind      Kind
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10, 'J', 'F', 'I', 'F'}, ContainerJPEG, KindImage},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), ContainerPNG, KindImage},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00"), ContainerGIF, KindImage},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), ContainerWebP, KindImage},
+		{"mp4", ftyp("isom", "isom", "iso2", "avc1", "mp41"), ContainerMP4, KindVideo},
+		{"mov", ftyp("qt  ", "qt  "), ContainerMOV, KindVideo},
+		{"legacy mov", []byte("\x00\x00\x00\x08wide\x00\x00\x00\x00mdat"), ContainerMOV, KindVideo},
+		{"heic", ftyp("heic", "mif1", "heic"), ContainerHEIC, KindImage},
+		{"compatible brand", ftyp("XXXX", "mp42"), ContainerMP4, KindVideo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Sniff(tt.header)
+			if err != nil {
+				t.Fatalf("sniff: %v", err)
+			}
+
+			if info.Container != tt.container || info.Kind != tt.kind || info.MIMEType == "" {
+				t.Fatalf("unexpected info: %#v", info)
+			}
+		})
+	}
+}
+
+func TestSniffRejectsUnknown(t *testing.T) {
+	for _, header := range [][]byte{
+		nil,
+		[]byte("%PDF-1.7"),
+		ftyp("M4A ", "M4A ", "isom"),
+	} {
+		if _, err := Sniff(header); !errors.Is(err, ErrUnknownFormat) {
+			t.Fatalf("expected ErrUnknownFormat for %q, got %v", header, err)
+		}
+	}
+}
+
+func TestDetectFileIgnoresExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(path, ftyp("qt  ", "qt  "), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	info, err := DetectFile(path)
+	if err != nil {
+		t.Fatalf("detect: %v", err)
+	}
+
+	if info.Container != ContainerMOV || info.MIMEType != "video/quicktime" {
+		t.Fatalf("expected mov, got %#v", info)
+	}
+}
+
+func TestFromExtensionAndContentType(t *testing.T) {
+	info, err := FromExtension("https://cdn.example.com/a/B.JPG")
+	if err != nil || info.Container != ContainerJPEG {
+		t.Fatalf("unexpected extension result: %#v, %v", info, err)
+	}
+
+	info, err = FromContentType("video/quicktime; charset=binary")
+	if err != nil || info.Container != ContainerMOV {
+		t.Fatalf("unexpected content type result: %#v, %v", info, err)
+	}
+
+	if _, err = FromExtension("export"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+
+	if _, err = FromContentType("application/octet-stream"); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+package media
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("unrecognized media format")
+	ErrInvalidImage  = errors.New("image does not meet instagram's rules")
+	ErrTooLarge      = errors.New("image too large")
+)
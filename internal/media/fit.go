@@ -0,0 +1,207 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+type FitMode string
+
+const (
+	FitNone FitMode = "none"
+	FitCrop FitMode = "crop"
+	FitPad  FitMode = "pad"
+)
+
+// blurScale is how far the padding copy is shrunk before being stretched
+// back, which is what blurs it.
+const blurScale = 24
+
+// FitOptions controls how an image is brought into an aspect ratio range.
+type FitOptions struct {
+	Mode      FitMode
+	MinAspect float64
+	MaxAspect float64
+	// FocusX and FocusY (0-1) are the point a crop is kept around. The zero
+	// value is the top left corner; use 0.5, 0.5 for the center.
+	FocusX float64
+	FocusY float64
+	// PadColor fills the padding. When nil, a blurred copy of the image is
+	// used instead.
+	PadColor color.Color
+}
+
+// FitRange returns the aspect ratios images are fitted to for target: its
+// limits, or the ratio it is shown at when it has none.
+func FitRange(target Target) (minAspect, maxAspect float64) {
+	spec := imageSpecs[target]
+	if spec.MinAspect > 0 || spec.MaxAspect > 0 {
+		return spec.MinAspect, spec.MaxAspect
+	}
+
+	if target == TargetStory {
+		return 9.0 / 16.0, 9.0 / 16.0
+	}
+
+	return 0, 0
+}
+
+// Needed reports whether an image with the given size would be changed.
+func (o FitOptions) Needed(width, height int) bool {
+	if o.Mode != FitCrop && o.Mode != FitPad || width <= 0 || height <= 0 {
+		return false
+	}
+
+	aspect := float64(width) / float64(height)
+	return o.MinAspect > 0 && aspect < o.MinAspect-aspectTolerance ||
+		o.MaxAspect > 0 && aspect > o.MaxAspect+aspectTolerance
+}
+
+// Fit crops or pads src to the nearest aspect ratio in range. Images already
+// in range are returned as they are.
+func Fit(src image.Image, opts FitOptions) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if !opts.Needed(width, height) {
+		return src
+	}
+
+	target := opts.aspect(width, height)
+	if opts.Mode == FitCrop {
+		return crop(src, target, opts.FocusX, opts.FocusY)
+	}
+
+	return pad(src, target, opts.PadColor)
+}
+
+// MapPoint returns where the point x, y (0-1) of a width x height image ends
+// up, again as 0-1, in the image Fit makes of it. A point the crop cuts off
+// is moved to the nearest edge and ok is false.
+func (o FitOptions) MapPoint(width, height int, x, y float64) (float64, float64, bool) {
+	if !o.Needed(width, height) {
+		return x, y, true
+	}
+
+	px, py := x*float64(width), y*float64(height)
+	target := o.aspect(width, height)
+
+	if o.Mode == FitCrop {
+		r := cropRect(width, height, target, o.FocusX, o.FocusY)
+		mx := (px - float64(r.Min.X)) / float64(r.Dx())
+		my := (py - float64(r.Min.Y)) / float64(r.Dy())
+		cx, cy := min(max(mx, 0), 1), min(max(my, 0), 1)
+
+		return cx, cy, cx == mx && cy == my
+	}
+
+	canvas, placed := padRect(width, height, target)
+	return (float64(placed.Min.X) + px) / float64(canvas.X), (float64(placed.Min.Y) + py) / float64(canvas.Y), true
+}
+
+// aspect returns the aspect ratio in range nearest to that of a width x
+// height image.
+func (o FitOptions) aspect(width, height int) float64 {
+	target := float64(width) / float64(height)
+	if o.MaxAspect > 0 && target > o.MaxAspect {
+		target = o.MaxAspect
+	}
+
+	if o.MinAspect > 0 && target < o.MinAspect {
+		target = o.MinAspect
+	}
+
+	return target
+}
+
+// crop cuts src to aspect, keeping the window as close to centered on the
+// focus point as the edges allow.
+func crop(src image.Image, aspect, focusX, focusY float64) image.Image {
+	bounds := src.Bounds()
+	r := cropRect(bounds.Dx(), bounds.Dy(), aspect, focusX, focusY)
+
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min.Add(r.Min), draw.Src)
+
+	return dst
+}
+
+// cropRect returns the part of a width x height image crop keeps.
+func cropRect(width, height int, aspect, focusX, focusY float64) image.Rectangle {
+	cropW, cropH := width, height
+	if float64(width)/float64(height) > aspect {
+		cropW = max(1, int(math.Round(float64(height)*aspect)))
+	} else {
+		cropH = max(1, int(math.Round(float64(width)/aspect)))
+	}
+
+	x := window(width, cropW, focusX)
+	y := window(height, cropH, focusY)
+
+	return image.Rect(x, y, x+cropW, y+cropH)
+}
+
+// window returns the offset of a span of size inside total, centered on
+// focus (0-1) and clamped to the edges.
+func window(total, size int, focus float64) int {
+	offset := int(math.Round(focus*float64(total) - float64(size)/2))
+	return min(max(offset, 0), total-size)
+}
+
+// pad letterboxes src to aspect, centered on a solid color or a blurred copy
+// of itself.
+func pad(src image.Image, aspect float64, background color.Color) image.Image {
+	bounds := src.Bounds()
+	canvas, placed := padRect(bounds.Dx(), bounds.Dy(), aspect)
+
+	dst := image.NewRGBA(image.Rectangle{Max: canvas})
+	if background != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	} else {
+		blurFill(dst, src)
+	}
+
+	draw.Draw(dst, placed, src, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// padRect returns the canvas size pad letterboxes a width x height image to
+// and where the image sits on it.
+func padRect(width, height int, aspect float64) (image.Point, image.Rectangle) {
+	canvasW, canvasH := width, height
+	if float64(width)/float64(height) > aspect {
+		canvasH = int(math.Round(float64(width) / aspect))
+	} else {
+		canvasW = int(math.Round(float64(height) * aspect))
+	}
+
+	offset := image.Pt((canvasW-width)/2, (canvasH-height)/2)
+
+	return image.Pt(canvasW, canvasH), image.Rectangle{Min: offset, Max: offset.Add(image.Pt(width, height))}
+}
+
+// blurFill covers dst with a blurred copy of src, scaled to fill it.
+func blurFill(dst *image.RGBA, src image.Image) {
+	bounds := src.Bounds()
+	canvas := dst.Bounds()
+
+	// Take the largest part of src with the canvas aspect ratio, like a
+	// centered crop, so the fill is not stretched.
+	aspect := float64(canvas.Dx()) / float64(canvas.Dy())
+	cropW, cropH := bounds.Dx(), bounds.Dy()
+	if float64(cropW)/float64(cropH) > aspect {
+		cropW = max(1, int(math.Round(float64(cropH)*aspect)))
+	} else {
+		cropH = max(1, int(math.Round(float64(cropW)/aspect)))
+	}
+
+	srcRect := image.Rect(0, 0, cropW, cropH).Add(bounds.Min).
+		Add(image.Pt((bounds.Dx()-cropW)/2, (bounds.Dy()-cropH)/2))
+
+	small := image.NewRGBA(image.Rect(0, 0, max(1, canvas.Dx()/blurScale), max(1, canvas.Dy()/blurScale)))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), src, srcRect, draw.Src, nil)
+	draw.BiLinear.Scale(dst, canvas, small, small.Bounds(), draw.Src, nil)
+}
@@ -0,0 +1,124 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// halves returns an image whose left half is black and right half white.
+func halves(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			if x < width/2 {
+				img.Set(x, y, color.Black)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+
+	return img
+}
+
+func TestFitCropKeepsFocus(t *testing.T) {
+	src := halves(3000, 1000)
+
+	fitted := Fit(src, FitOptions{Mode: FitCrop, MinAspect: 0.8, MaxAspect: 1.91, FocusX: 1, FocusY: 0.5})
+	if fitted.Bounds().Dx() != 1910 || fitted.Bounds().Dy() != 1000 {
+		t.Fatalf("unexpected size: %v", fitted.Bounds())
+	}
+
+	// Focused on the right edge, so the window sits against it.
+	if r, _, _, _ := fitted.At(fitted.Bounds().Dx()-1, 0).RGBA(); r != 0xFFFF {
+		t.Fatalf("expected the right edge to be kept")
+	}
+	if r, _, _, _ := fitted.At(0, 0).RGBA(); r != 0 {
+		t.Fatalf("expected part of the black half to be kept")
+	}
+
+	tall := Fit(halves(1000, 2000), FitOptions{Mode: FitCrop, MinAspect: 0.8, MaxAspect: 1.91, FocusX: 0.5, FocusY: 0.5})
+	if tall.Bounds().Dx() != 1000 || tall.Bounds().Dy() != 1250 {
+		t.Fatalf("unexpected size: %v", tall.Bounds())
+	}
+}
+
+func TestFitPadWithColor(t *testing.T) {
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	fitted := Fit(halves(1000, 2000), FitOptions{Mode: FitPad, MinAspect: 0.8, MaxAspect: 1.91, PadColor: red})
+
+	if fitted.Bounds().Dx() != 1600 || fitted.Bounds().Dy() != 2000 {
+		t.Fatalf("unexpected size: %v", fitted.Bounds())
+	}
+
+	if got := color.RGBAModel.Convert(fitted.At(0, 1000)); got != red {
+		t.Fatalf("expected red padding, got %v", got)
+	}
+	if got := color.RGBAModel.Convert(fitted.At(1599, 1000)); got != red {
+		t.Fatalf("expected red padding, got %v", got)
+	}
+	if r, _, _, _ := fitted.At(300, 1000).RGBA(); r != 0 {
+		t.Fatalf("expected the image in the middle")
+	}
+}
+
+func TestFitPadWithBlur(t *testing.T) {
+	fitted := Fit(halves(3000, 1000), FitOptions{Mode: FitPad, MinAspect: 0.8, MaxAspect: 1.91})
+	if fitted.Bounds().Dx() != 3000 || fitted.Bounds().Dy() != 1571 {
+		t.Fatalf("unexpected size: %v", fitted.Bounds())
+	}
+
+	// The blurred fill follows the image: dark on the left, light on the
+	// right.
+	left, _, _, _ := fitted.At(10, 5).RGBA()
+	right, _, _, _ := fitted.At(2990, 5).RGBA()
+	if left >= right {
+		t.Fatalf("expected blurred copy of the image, got %d and %d", left>>8, right>>8)
+	}
+}
+
+func TestFitLeavesImagesInRange(t *testing.T) {
+	src := halves(1080, 1350)
+	if Fit(src, FitOptions{Mode: FitCrop, MinAspect: 0.8, MaxAspect: 1.91}) != src {
+		t.Fatalf("expected image in range to be unchanged")
+	}
+
+	if (FitOptions{Mode: FitNone, MinAspect: 0.8}).Needed(100, 1000) {
+		t.Fatalf("expected none to never fit")
+	}
+
+	minAspect, maxAspect := FitRange(TargetStory)
+	if minAspect != 9.0/16.0 || maxAspect != 9.0/16.0 {
+		t.Fatalf("unexpected story range: %v-%v", minAspect, maxAspect)
+	}
+}
+
+func TestFitMapPoint(t *testing.T) {
+	near := func(a, b float64) bool { return a-b < 1e-3 && b-a < 1e-3 }
+
+	// 3000x1000 cropped to 1910x1000 around the center keeps x 545-2455.
+	crop := FitOptions{Mode: FitCrop, MinAspect: 0.8, MaxAspect: 1.91, FocusX: 0.5, FocusY: 0.5}
+	if x, y, ok := crop.MapPoint(3000, 1000, 0.5, 0.25); !ok || !near(x, 0.5) || !near(y, 0.25) {
+		t.Fatalf("unexpected center point: %v,%v %v", x, y, ok)
+	}
+	if x, _, ok := crop.MapPoint(3000, 1000, 0.25, 0.5); !ok || !near(x, (750-545)/1910.0) {
+		t.Fatalf("unexpected cropped point: %v %v", x, ok)
+	}
+	if x, _, ok := crop.MapPoint(3000, 1000, 0.1, 0.5); ok || x != 0 {
+		t.Fatalf("expected a cut off point moved to the edge, got %v %v", x, ok)
+	}
+
+	// 1000x2000 padded to 1600x2000 sits at x 300-1300.
+	pad := FitOptions{Mode: FitPad, MinAspect: 0.8, MaxAspect: 1.91}
+	if x, y, ok := pad.MapPoint(1000, 2000, 0, 0.5); !ok || !near(x, 300/1600.0) || !near(y, 0.5) {
+		t.Fatalf("unexpected padded point: %v,%v %v", x, y, ok)
+	}
+	if x, _, _ := pad.MapPoint(1000, 2000, 1, 0.5); !near(x, 1300/1600.0) {
+		t.Fatalf("unexpected padded point: %v", x)
+	}
+
+	if x, y, ok := pad.MapPoint(1080, 1350, 0.3, 0.7); !ok || x != 0.3 || y != 0.7 {
+		t.Fatalf("expected an image in range to be left alone, got %v,%v", x, y)
+	}
+}
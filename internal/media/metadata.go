@@ -0,0 +1,405 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// JPEG markers read or dropped when stripping metadata.
+const (
+	markerSOI   = 0xD8
+	markerEOI   = 0xD9
+	markerSOS   = 0xDA
+	markerAPP0  = 0xE0
+	markerAPP1  = 0xE1
+	markerAPP13 = 0xED
+	markerCOM   = 0xFE
+)
+
+// EXIF tags and field types poster reads and writes.
+const (
+	tagOrientation = 0x0112
+	tagArtist      = 0x013B
+	tagCopyright   = 0x8298
+
+	exifTypeASCII = 2
+	exifTypeShort = 3
+)
+
+var exifPrefix = []byte("Exif\x00\x00")
+
+// Metadata is what poster reads from a JPEG's EXIF before dropping it.
+type Metadata struct {
+	// Orientation is the EXIF orientation, 1 (upright) to 8.
+	Orientation int
+	Artist      string
+	Copyright   string
+	// Present reports whether the file has EXIF, XMP, IPTC or comment
+	// segments.
+	Present bool
+}
+
+// Size returns the size an image of width x height is shown at once its
+// orientation is applied.
+func (m Metadata) Size(width, height int) (int, int) {
+	if m.Orientation >= 5 && m.Orientation <= 8 {
+		return height, width
+	}
+
+	return width, height
+}
+
+// Rotated reports whether the pixels have to be turned or flipped to show
+// the image upright.
+func (m Metadata) Rotated() bool {
+	return m.Orientation >= 2 && m.Orientation <= 8
+}
+
+// ReadMetadata reads the orientation and copyright of the JPEG at path.
+// Other formats report no metadata.
+func ReadMetadata(path string) (Metadata, error) {
+	// #nosec G304 -- path is user-provided
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("read file: %w", err)
+	}
+
+	meta, err := readMetadata(data)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return meta, nil
+}
+
+func readMetadata(data []byte) (Metadata, error) {
+	meta := Metadata{Orientation: 1}
+	if !isJPEG(data) {
+		return meta, nil
+	}
+
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return meta, err
+	}
+
+	for _, seg := range segments {
+		if !seg.metadata() {
+			continue
+		}
+
+		meta.Present = true
+		if seg.marker == markerAPP1 && bytes.HasPrefix(seg.payload, exifPrefix) {
+			parseEXIF(seg.payload[len(exifPrefix):], &meta)
+		}
+	}
+
+	return meta, nil
+}
+
+// StripMetadata writes a copy of the JPEG at path to opts.Dir without its
+// EXIF, XMP, IPTC and comment segments and returns the new path. The image
+// data is copied as it is, so there is no quality loss. Only Dir and
+// KeepCopyright are used from opts.
+func StripMetadata(path string, opts ConvertOptions) (string, error) {
+	// #nosec G304 -- path is user-provided
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+
+	if !isJPEG(data) {
+		return "", fmt.Errorf("%s: %w: not a jpeg", path, ErrUnknownFormat)
+	}
+
+	segments, scan, err := jpegSegments(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	meta, err := readMetadata(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	var keep []byte
+	if opts.KeepCopyright {
+		keep = exifSegment(0, meta.Artist, meta.Copyright)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, markerSOI)
+	for i, seg := range segments {
+		// EXIF goes right after SOI, or after the JFIF header when there is
+		// one.
+		if keep != nil && (i > 0 || seg.marker != markerAPP0) {
+			out = append(out, keep...)
+			keep = nil
+		}
+
+		if !seg.metadata() {
+			out = append(out, seg.raw...)
+		}
+	}
+
+	out = append(out, keep...)
+	out = append(out, scan...)
+
+	return writeTemp(opts.Dir, path, out)
+}
+
+// Orient turns or flips src as the EXIF orientation says, so it shows
+// upright without the tag.
+func Orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dstW, dstH := Metadata{Orientation: orientation}.Size(width, height)
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := range height {
+		for x := range width {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = width-1-x, y
+			case 3: // upside down
+				dx, dy = width-1-x, height-1-y
+			case 4: // mirrored upside down
+				dx, dy = x, height-1-y
+			case 5: // mirrored, turned left
+				dx, dy = y, x
+			case 6: // turned left, so rotate clockwise
+				dx, dy = height-1-y, x
+			case 7: // mirrored, turned right
+				dx, dy = height-1-y, width-1-x
+			case 8: // turned right, so rotate counterclockwise
+				dx, dy = y, width-1-x
+			}
+
+			from := rgba.PixOffset(x, y)
+			to := dst.PixOffset(dx, dy)
+			copy(dst.Pix[to:to+4], rgba.Pix[from:from+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegSegment is one marker segment before the image data.
+type jpegSegment struct {
+	marker  byte
+	raw     []byte
+	payload []byte
+}
+
+// metadata reports whether the segment holds EXIF or XMP (APP1), IPTC
+// (APP13) or a comment.
+func (s jpegSegment) metadata() bool {
+	return s.marker == markerAPP1 || s.marker == markerAPP13 || s.marker == markerCOM
+}
+
+func isJPEG(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0xFF && data[1] == markerSOI
+}
+
+// jpegSegments splits a JPEG after its SOI marker into the segments before
+// the image data and the rest, which starts at the first SOS marker.
+func jpegSegments(data []byte) ([]jpegSegment, []byte, error) {
+	var segments []jpegSegment
+	pos := 2
+	for {
+		if pos >= len(data) || data[pos] != 0xFF {
+			return nil, nil, fmt.Errorf("%w: malformed jpeg at byte %d", ErrUnknownFormat, pos)
+		}
+
+		start := pos
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+
+		if pos >= len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated jpeg", ErrUnknownFormat)
+		}
+
+		marker := data[pos]
+		pos++
+
+		switch {
+		case marker == markerSOS || marker == markerEOI:
+			return segments, data[start:], nil
+		case marker >= 0xD0 && marker <= 0xD7, marker == 0x01:
+			segments = append(segments, jpegSegment{marker: marker, raw: data[start:pos]})
+			continue
+		}
+
+		if pos+2 > len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated jpeg", ErrUnknownFormat)
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 || pos+length > len(data) {
+			return nil, nil, fmt.Errorf("%w: truncated jpeg", ErrUnknownFormat)
+		}
+
+		segments = append(segments, jpegSegment{
+			marker:  marker,
+			raw:     data[start : pos+length],
+			payload: data[pos+2 : pos+length],
+		})
+		pos += length
+	}
+}
+
+// parseEXIF reads the orientation, artist and copyright from the first IFD
+// of a TIFF-structured EXIF block. Malformed entries are skipped.
+func parseEXIF(tiff []byte, meta *Metadata) {
+	if len(tiff) < 8 {
+		return
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := range count {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+
+		tag := order.Uint16(tiff[entry:])
+		kind := order.Uint16(tiff[entry+2:])
+		n := int(order.Uint32(tiff[entry+4:]))
+		value := tiff[entry+8 : entry+12]
+
+		switch {
+		case tag == tagOrientation && kind == exifTypeShort:
+			if o := int(order.Uint16(value)); o >= 1 && o <= 8 {
+				meta.Orientation = o
+			}
+		case (tag == tagArtist || tag == tagCopyright) && kind == exifTypeASCII:
+			text := exifString(tiff, order, value, n)
+			if tag == tagArtist {
+				meta.Artist = text
+			} else {
+				meta.Copyright = text
+			}
+		}
+	}
+}
+
+// exifString returns an ASCII value, stored in place when it fits in four
+// bytes and at an offset otherwise.
+func exifString(tiff []byte, order binary.ByteOrder, value []byte, n int) string {
+	raw := value
+	if n > 4 {
+		offset := int(order.Uint32(value))
+		if offset < 0 || offset+n > len(tiff) {
+			return ""
+		}
+
+		raw = tiff[offset : offset+n]
+	} else {
+		raw = raw[:n]
+	}
+
+	return strings.TrimSpace(strings.TrimRight(string(raw), "\x00"))
+}
+
+// exifSegment builds an APP1 segment holding only the given fields; zero
+// values are left out. It returns nil when there is nothing to write.
+func exifSegment(orientation int, artist, copyright string) []byte {
+	type field struct {
+		tag   uint16
+		kind  uint16
+		short uint16
+		text  string
+	}
+
+	// Entries have to be in tag order.
+	var fields []field
+	if orientation > 0 {
+		fields = append(fields, field{tag: tagOrientation, kind: exifTypeShort, short: uint16(orientation)})
+	}
+
+	if artist != "" {
+		fields = append(fields, field{tag: tagArtist, kind: exifTypeASCII, text: artist})
+	}
+
+	if copyright != "" {
+		fields = append(fields, field{tag: tagCopyright, kind: exifTypeASCII, text: copyright})
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	order := binary.BigEndian
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8}
+	tiff = order.AppendUint16(tiff, uint16(len(fields)))
+
+	// Values over four bytes go after the IFD and its next-IFD offset.
+	extra := 8 + 2 + len(fields)*12 + 4
+	var data []byte
+	for _, f := range fields {
+		tiff = order.AppendUint16(tiff, f.tag)
+		tiff = order.AppendUint16(tiff, f.kind)
+
+		if f.kind == exifTypeShort {
+			tiff = order.AppendUint32(tiff, 1)
+			tiff = order.AppendUint16(tiff, f.short)
+			tiff = append(tiff, 0, 0)
+			continue
+		}
+
+		text := append([]byte(f.text), 0)
+		tiff = order.AppendUint32(tiff, uint32(len(text))) // #nosec G115 -- bounded by the segment size check below
+		if len(text) <= 4 {
+			tiff = append(tiff, append(text, make([]byte, 4-len(text))...)...)
+			continue
+		}
+
+		tiff = order.AppendUint32(tiff, uint32(extra+len(data))) // #nosec G115 -- bounded by the segment size check below
+		data = append(data, text...)
+	}
+
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, data...)
+
+	length := 2 + len(exifPrefix) + len(tiff)
+	if length > 0xFFFF {
+		return nil
+	}
+
+	seg := []byte{0xFF, markerAPP1}
+	seg = order.AppendUint16(seg, uint16(length))
+	seg = append(seg, exifPrefix...)
+
+	return append(seg, tiff...)
+}
@@ -0,0 +1,151 @@
+package media
+
+import (
+	"bytes"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testCopyright = "(c) 2026 Example Studio"
+
+// writeTaggedJPEG writes a JPEG of halves(width, height) with an EXIF block,
+// an XMP packet and a comment, the way phone cameras do.
+func writeTaggedJPEG(t *testing.T, width, height, orientation int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, halves(width, height), nil); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+
+	xmp := append([]byte{0xFF, markerAPP1, 0, 0}, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")...)
+	xmp[3] = byte(len(xmp) - 2)
+	comment := append([]byte{0xFF, markerCOM, 0, 0}, []byte("taken at home")...)
+	comment[3] = byte(len(comment) - 2)
+
+	data := buf.Bytes()
+	tagged := append([]byte{0xFF, markerSOI}, exifSegment(orientation, "Jo", testCopyright)...)
+	tagged = append(tagged, xmp...)
+	tagged = append(tagged, comment...)
+	tagged = append(tagged, data[2:]...)
+
+	path := filepath.Join(t.TempDir(), "phone.jpg")
+	if err := os.WriteFile(path, tagged, 0o600); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	return path
+}
+
+func TestReadMetadata(t *testing.T) {
+	meta, err := ReadMetadata(writeTaggedJPEG(t, 40, 20, 6))
+	if err != nil {
+		t.Fatalf("read metadata: %v", err)
+	}
+
+	want := Metadata{Orientation: 6, Artist: "Jo", Copyright: testCopyright, Present: true}
+	if meta != want {
+		t.Fatalf("unexpected metadata: %#v", meta)
+	}
+
+	if w, h := meta.Size(40, 20); w != 20 || h != 40 {
+		t.Fatalf("unexpected oriented size: %dx%d", w, h)
+	}
+
+	png, err := ReadMetadata(writeNoisePNG(t, 4, 4, 0xFF))
+	if err != nil || png.Present || png.Rotated() {
+		t.Fatalf("expected no metadata for png, got %#v, %v", png, err)
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	path := writeTaggedJPEG(t, 40, 20, 1)
+	dir := t.TempDir()
+
+	out, err := StripMetadata(path, ConvertOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+
+	if meta, err := ReadMetadata(out); err != nil || meta != (Metadata{Orientation: 1}) {
+		t.Fatalf("expected no metadata, got %#v, %v", meta, err)
+	}
+
+	original, _ := os.ReadFile(path) // #nosec G304 -- test file
+	stripped, _ := os.ReadFile(out)  // #nosec G304 -- test file
+	_, scan, err := jpegSegments(original)
+	if err != nil {
+		t.Fatalf("parse original: %v", err)
+	}
+	if !bytes.HasSuffix(stripped, scan) {
+		t.Fatalf("expected image data to be copied unchanged")
+	}
+
+	kept, err := StripMetadata(path, ConvertOptions{Dir: dir, KeepCopyright: true})
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+
+	want := Metadata{Orientation: 1, Artist: "Jo", Copyright: testCopyright, Present: true}
+	if meta, err := ReadMetadata(kept); err != nil || meta != want {
+		t.Fatalf("expected copyright only, got %#v, %v", meta, err)
+	}
+
+	if _, err = DecodeImage(kept); err != nil {
+		t.Fatalf("decode stripped image: %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := halves(2, 1)
+
+	cw := Orient(src, 6)
+	if cw.Bounds().Dx() != 1 || cw.Bounds().Dy() != 2 {
+		t.Fatalf("unexpected size: %v", cw.Bounds())
+	}
+	if got := color.GrayModel.Convert(cw.At(0, 0)); got != (color.Gray{}) {
+		t.Fatalf("expected the left half on top, got %v", got)
+	}
+
+	ccw := Orient(src, 8)
+	if got := color.GrayModel.Convert(ccw.At(0, 1)); got != (color.Gray{}) {
+		t.Fatalf("expected the left half at the bottom, got %v", got)
+	}
+
+	mirrored := Orient(src, 2)
+	if got := color.GrayModel.Convert(mirrored.At(1, 0)); got != (color.Gray{}) {
+		t.Fatalf("expected the left half on the right, got %v", got)
+	}
+
+	if Orient(src, 1) != src {
+		t.Fatalf("expected upright image to be unchanged")
+	}
+}
+
+func TestToJPEGAppliesOrientation(t *testing.T) {
+	path := writeTaggedJPEG(t, 200, 100, 6)
+
+	out, err := ToJPEG(path, ConvertOptions{Dir: t.TempDir(), KeepCopyright: true})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+
+	img, err := DecodeImage(out)
+	if err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if img.Width != 100 || img.Height != 200 {
+		t.Fatalf("expected the image turned upright, got %dx%d", img.Width, img.Height)
+	}
+
+	meta, err := ReadMetadata(out)
+	if err != nil {
+		t.Fatalf("read metadata: %v", err)
+	}
+	if meta.Rotated() || meta.Copyright != testCopyright {
+		t.Fatalf("unexpected metadata: %#v", meta)
+	}
+}
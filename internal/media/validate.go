@@ -0,0 +1,254 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF header decoding
+	_ "image/jpeg" // register JPEG header decoding
+	_ "image/png"  // register PNG header decoding
+	"os"
+	"strings"
+
+	_ "golang.org/x/image/webp" // register WebP header decoding
+)
+
+// MaxImageBytes is the largest image Instagram accepts.
+const MaxImageBytes = 8 << 20
+
+// aspectTolerance absorbs rounding in pixel sizes, so 1080x1350 counts as
+// exactly 4:5.
+const aspectTolerance = 0.005
+
+// Target is where an image is published. Each target has its own rules.
+type Target string
+
+const (
+	TargetFeed     Target = "feed"
+	TargetCarousel Target = "carousel"
+	TargetStory    Target = "story"
+)
+
+// ImageSpec is what Instagram accepts for images on a target. Zero limits
+// are not checked.
+type ImageSpec struct {
+	JPEGOnly  bool
+	MinAspect float64
+	MaxAspect float64
+	// Images outside the width range are scaled by Instagram, so they are
+	// reported as warnings rather than violations.
+	MinWidth int
+	MaxWidth int
+	MaxBytes int64
+}
+
+var imageSpecs = map[Target]ImageSpec{
+	TargetFeed: {
+		JPEGOnly:  true,
+		MinAspect: 4.0 / 5.0,
+		MaxAspect: 1.91,
+		MinWidth:  320,
+		MaxWidth:  1440,
+		MaxBytes:  MaxImageBytes,
+	},
+	TargetCarousel: {
+		JPEGOnly:  true,
+		MinAspect: 4.0 / 5.0,
+		MaxAspect: 1.91,
+		MinWidth:  320,
+		MaxWidth:  1440,
+		MaxBytes:  MaxImageBytes,
+	},
+	TargetStory: {
+		JPEGOnly: true,
+		MinWidth: 320,
+		MaxBytes: MaxImageBytes,
+	},
+}
+
+// Targets returns the known targets.
+func Targets() []Target {
+	return []Target{TargetFeed, TargetCarousel, TargetStory}
+}
+
+// SpecFor returns the image rules for target.
+func SpecFor(target Target) (ImageSpec, bool) {
+	spec, ok := imageSpecs[target]
+	return spec, ok
+}
+
+// Image is an image file as read from its header.
+type Image struct {
+	Info
+	Width  int
+	Height int
+	Size   int64
+}
+
+func (i Image) Aspect() float64 {
+	if i.Height == 0 {
+		return 0
+	}
+
+	return float64(i.Width) / float64(i.Height)
+}
+
+// DecodeImage identifies the image at path and reads its dimensions from the
+// header, without decoding the pixels.
+func DecodeImage(path string) (Image, error) {
+	info, err := DetectFile(path)
+	if err != nil {
+		return Image{}, err
+	}
+
+	if info.Kind != KindImage {
+		return Image{}, fmt.Errorf("%w: %s is not an image", ErrUnknownFormat, info.Container)
+	}
+
+	// #nosec G304 -- path is user-provided
+	file, err := os.Open(path)
+	if err != nil {
+		return Image{}, fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return Image{}, fmt.Errorf("stat file: %w", err)
+	}
+
+	img := Image{Info: info, Size: stat.Size()}
+	if info.Container == ContainerHEIC {
+		return img, nil
+	}
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return Image{}, fmt.Errorf("read %s header: %w", info.Container, err)
+	}
+
+	img.Width = config.Width
+	img.Height = config.Height
+	return img, nil
+}
+
+// Violation is one way an image breaks a target's rules.
+type Violation struct {
+	// Rule is format, aspect_ratio, width or file_size.
+	Rule    string
+	Message string
+	// Warning marks problems Instagram fixes on its own, such as a width it
+	// will scale. They do not make the image invalid.
+	Warning bool
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Check returns every way img breaks the spec, in a stable order.
+func (s ImageSpec) Check(img Image) []Violation {
+	var violations []Violation
+
+	if img.Container == ContainerHEIC {
+		violations = append(violations, Violation{Rule: "format", Message: "HEIC images are not supported"})
+	} else if s.JPEGOnly && img.Container != ContainerJPEG {
+		violations = append(violations, Violation{Rule: "format", Message: fmt.Sprintf("%s image, but Instagram requires JPEG", strings.ToUpper(img.Container))})
+	}
+
+	if img.Width > 0 && img.Height > 0 {
+		aspect := img.Aspect()
+		if s.MinAspect > 0 && aspect < s.MinAspect-aspectTolerance {
+			violations = append(violations, Violation{
+				Rule:    "aspect_ratio",
+				Message: fmt.Sprintf("%dx%d is %.2f:1, taller than the minimum of %.2f:1", img.Width, img.Height, aspect, s.MinAspect),
+			})
+		}
+
+		if s.MaxAspect > 0 && aspect > s.MaxAspect+aspectTolerance {
+			violations = append(violations, Violation{
+				Rule:    "aspect_ratio",
+				Message: fmt.Sprintf("%dx%d is %.2f:1, wider than the maximum of %.2f:1", img.Width, img.Height, aspect, s.MaxAspect),
+			})
+		}
+
+		if s.MinWidth > 0 && img.Width < s.MinWidth {
+			violations = append(violations, Violation{
+				Rule:    "width",
+				Message: fmt.Sprintf("%dpx is below the minimum of %dpx; Instagram will scale it up", img.Width, s.MinWidth),
+				Warning: true,
+			})
+		}
+
+		if s.MaxWidth > 0 && img.Width > s.MaxWidth {
+			violations = append(violations, Violation{
+				Rule:    "width",
+				Message: fmt.Sprintf("%dpx is above the maximum of %dpx; Instagram will scale it down", img.Width, s.MaxWidth),
+				Warning: true,
+			})
+		}
+	}
+
+	if s.MaxBytes > 0 && img.Size > s.MaxBytes {
+		violations = append(violations, Violation{
+			Rule:    "file_size",
+			Message: fmt.Sprintf("%d bytes is over the %d byte limit", img.Size, s.MaxBytes),
+		})
+	}
+
+	return violations
+}
+
+// ValidationError lists the rules an image breaks. Warnings are not
+// included.
+type ValidationError struct {
+	Path       string
+	Target     Target
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s does not meet Instagram's %s image rules:", e.Path, e.Target)
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n  - %s", v)
+	}
+
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidImage
+}
+
+// ValidateImage checks the image at path against target's rules. It returns
+// the decoded header and all violations, warnings included. The error is a
+// *ValidationError when any violation is not a warning.
+func ValidateImage(path string, target Target) (Image, []Violation, error) {
+	spec, ok := SpecFor(target)
+	if !ok {
+		return Image{}, nil, fmt.Errorf("unknown target %q", target)
+	}
+
+	img, err := DecodeImage(path)
+	if err != nil {
+		return Image{}, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	violations := spec.Check(img)
+
+	var failures []Violation
+	for _, v := range violations {
+		if !v.Warning {
+			failures = append(failures, v)
+		}
+	}
+
+	if len(failures) > 0 {
+		return img, violations, &ValidationError{Path: path, Target: target, Violations: failures}
+	}
+
+	return img, violations, nil
+}
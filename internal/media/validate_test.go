@@ -0,0 +1,98 @@
+package media
+
+import (
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeImage(t *testing.T, name string, width, height int) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path) // #nosec G304 -- test file
+	if err != nil {
+		t.Fatalf("create image: %v", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if filepath.Ext(name) == ".png" {
+		err = png.Encode(file, img)
+	} else {
+		err = jpeg.Encode(file, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+
+	return path
+}
+
+func TestValidateImageAcceptsFeedJPEG(t *testing.T) {
+	img, violations, err := ValidateImage(writeImage(t, "photo.jpg", 1080, 1350), TargetFeed)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if len(violations) != 0 {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+
+	if img.Width != 1080 || img.Height != 1350 || img.Container != ContainerJPEG {
+		t.Fatalf("unexpected image: %#v", img)
+	}
+}
+
+func TestValidateImageListsEveryViolation(t *testing.T) {
+	path := writeImage(t, "banner.png", 2000, 500)
+
+	_, violations, err := ValidateImage(path, TargetCarousel)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrInvalidImage) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	rules := make([]string, 0, len(validationErr.Violations))
+	for _, v := range validationErr.Violations {
+		rules = append(rules, v.Rule)
+	}
+
+	if len(rules) != 2 || rules[0] != "format" || rules[1] != "aspect_ratio" {
+		t.Fatalf("unexpected violations: %v", validationErr.Violations)
+	}
+
+	// The width is only a warning: Instagram scales it down.
+	if len(violations) != 3 || violations[2].Rule != "width" || !violations[2].Warning {
+		t.Fatalf("expected width warning, got %v", violations)
+	}
+}
+
+func TestValidateImageStoryHasNoAspectLimit(t *testing.T) {
+	_, violations, err := ValidateImage(writeImage(t, "story.jpg", 1080, 1920), TargetStory)
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("expected a valid story, got %v, %v", violations, err)
+	}
+}
+
+func TestImageSpecCheckFileSize(t *testing.T) {
+	spec, _ := SpecFor(TargetFeed)
+	violations := spec.Check(Image{
+		Info:   Info{Kind: KindImage, Container: ContainerJPEG},
+		Width:  1080,
+		Height: 1080,
+		Size:   MaxImageBytes + 1,
+	})
+
+	if len(violations) != 1 || violations[0].Rule != "file_size" || violations[0].Warning {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+}
@@ -0,0 +1,53 @@
+// Package progress reports how many bytes of a file have been sent, for
+// uploads to hosting backends and to Meta alike.
+package progress
+
+import (
+	"context"
+	"io"
+)
+
+// Func is called as file bytes are sent. total is the file size, or -1 when
+// it is not known.
+type Func func(sent, total int64)
+
+type contextKey struct{}
+
+// With returns a context that makes uploads report progress to fn.
+func With(ctx context.Context, fn Func) context.Context {
+	return context.WithValue(ctx, contextKey{}, fn)
+}
+
+func fromContext(ctx context.Context) Func {
+	fn, _ := ctx.Value(contextKey{}).(Func)
+	return fn
+}
+
+// Track wraps r so reads are reported to the progress func in ctx, if there
+// is one. total is the number of bytes r will yield, or -1.
+func Track(ctx context.Context, r io.Reader, total int64) io.Reader {
+	fn := fromContext(ctx)
+	if fn == nil {
+		return r
+	}
+
+	fn(0, total)
+	return &progressReader{r: r, total: total, fn: fn}
+}
+
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	fn    Func
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.sent, p.total)
+	}
+
+	return n, err
+}
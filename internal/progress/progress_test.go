@@ -0,0 +1,28 @@
+package progress
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTrackReportsReads(t *testing.T) {
+	var last, total int64
+	ctx := With(context.Background(), func(sent, size int64) {
+		last, total = sent, size
+	})
+
+	if _, err := io.Copy(io.Discard, Track(ctx, strings.NewReader("hello"), 5)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if last != 5 || total != 5 {
+		t.Fatalf("expected 5 of 5 bytes reported, got %d of %d", last, total)
+	}
+
+	r := strings.NewReader("x")
+	if Track(context.Background(), r, 1) != r {
+		t.Fatalf("expected reader unchanged without a progress func")
+	}
+}
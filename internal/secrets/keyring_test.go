@@ -92,3 +92,40 @@ func TestDeleteMissingAccessToken(t *testing.T) {
 		t.Fatalf("expected deleted=false")
 	}
 }
+
+func TestS3SecretAccessKeyRoundTrip(t *testing.T) {
+	restore := withTestKeyring(t)
+	defer restore()
+
+	if err := SetS3SecretAccessKey("test", "s3-secret"); err != nil {
+		t.Fatalf("set s3 secret: %v", err)
+	}
+
+	got, ok, err := GetS3SecretAccessKey("test")
+	if err != nil {
+		t.Fatalf("get s3 secret: %v", err)
+	}
+
+	if !ok || got != "s3-secret" {
+		t.Fatalf("unexpected s3 secret: %q (ok=%t)", got, ok)
+	}
+
+	_, ok, err = GetAccessToken("test")
+	if err != nil {
+		t.Fatalf("get access token: %v", err)
+	}
+
+	if ok {
+		t.Fatalf("s3 secret must not be stored as the access token")
+	}
+
+	deleted, err := DeleteS3SecretAccessKey("test")
+	if err != nil || !deleted {
+		t.Fatalf("delete s3 secret: deleted=%t err=%v", deleted, err)
+	}
+
+	deleted, err = DeleteS3SecretAccessKey("test")
+	if err != nil || deleted {
+		t.Fatalf("delete missing s3 secret: deleted=%t err=%v", deleted, err)
+	}
+}
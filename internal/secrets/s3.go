@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/99designs/keyring"
+)
+
+func s3SecretKey(profile string) string {
+	return fmt.Sprintf("profile:%s:s3_secret_access_key", profile)
+}
+
+func SetS3SecretAccessKey(profile string, secret string) error {
+	kr, err := OpenKeyring()
+	if err != nil {
+		return err
+	}
+
+	item := keyring.Item{
+		Key:   s3SecretKey(profile),
+		Data:  []byte(secret),
+		Label: "poster s3 secret access key",
+	}
+
+	if err := kr.Set(item); err != nil {
+		return fmt.Errorf("store s3 secret access key: %w", err)
+	}
+
+	return nil
+}
+
+func GetS3SecretAccessKey(profile string) (string, bool, error) {
+	kr, err := OpenKeyring()
+	if err != nil {
+		return "", false, err
+	}
+
+	item, err := kr.Get(s3SecretKey(profile))
+	if err != nil {
+		if errors.Is(err, keyring.ErrKeyNotFound) {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("get s3 secret access key: %w", err)
+	}
+
+	return string(item.Data), true, nil
+}
+
+// DeleteS3SecretAccessKey removes the stored key; a missing key is not an error.
+func DeleteS3SecretAccessKey(profile string) (bool, error) {
+	kr, err := OpenKeyring()
+	if err != nil {
+		return false, err
+	}
+
+	if err := kr.Remove(s3SecretKey(profile)); err != nil {
+		if errors.Is(err, keyring.ErrKeyNotFound) || os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("delete s3 secret access key: %w", err)
+	}
+
+	return true, nil
+}
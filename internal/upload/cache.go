@@ -0,0 +1,350 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// cacheMinRemaining is how long a cached URL must stay valid to be
+	// reused. Meta may fetch media well after the container is created.
+	cacheMinRemaining = 15 * time.Minute
+	cacheProbeTimeout = 10 * time.Second
+)
+
+// CacheEntry is a hosted copy of a file, keyed by content hash, backend and
+// backend identity.
+type CacheEntry struct {
+	Backend       string    `json:"backend"`
+	Identity      string    `json:"identity,omitempty"`
+	SHA256        string    `json:"sha256"`
+	File          string    `json:"file"`
+	URL           string    `json:"url"`
+	DeleteHandle  string    `json:"delete_handle,omitempty"`
+	DeleteCommand []string  `json:"delete_command,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the entry is too close to expiry to be reused.
+func (e CacheEntry) Expired(now time.Time) bool {
+	return !now.Add(cacheMinRemaining).Before(e.ExpiresAt)
+}
+
+type cacheFile struct {
+	Entries map[string]CacheEntry `json:"entries"`
+}
+
+// Cache remembers where files were hosted so re-running a failed publish does
+// not upload the same content again. It is stored as a JSON file.
+type Cache struct {
+	path       string
+	HTTPClient *http.Client
+	now        func() time.Time
+}
+
+func NewCache(path string) *Cache {
+	return &Cache{
+		path:       path,
+		HTTPClient: http.DefaultClient,
+		now:        time.Now,
+	}
+}
+
+// Entries returns all cached entries, oldest first.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	data, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]CacheEntry, 0, len(data.Entries))
+	for _, entry := range data.Entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+
+	return entries, nil
+}
+
+// Lookup returns the entry for a file hash on a backend (and identity, see
+// Identifier) if it is still valid and its URL still answers.
+func (c *Cache) Lookup(ctx context.Context, backend, identity, sum string) (CacheEntry, bool, error) {
+	data, err := c.read()
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+
+	entry, ok := data.Entries[cacheKey(backend, identity, sum)]
+	if !ok || entry.Expired(c.now()) {
+		return CacheEntry{}, false, nil
+	}
+
+	if !c.reachable(ctx, entry.URL) {
+		slog.Debug("cached url unreachable", "url", entry.URL)
+		return CacheEntry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+// Store adds or replaces an entry and drops expired ones.
+func (c *Cache) Store(entry CacheEntry) error {
+	data, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	for key, existing := range data.Entries {
+		if existing.Expired(now) {
+			delete(data.Entries, key)
+		}
+	}
+
+	data.Entries[cacheKey(entry.Backend, entry.Identity, entry.SHA256)] = entry
+	return c.write(data)
+}
+
+// Remove drops the entries for rawURL, for example after the hosted copy was
+// deleted.
+func (c *Cache) Remove(rawURL string) error {
+	data, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	removed := false
+	for key, entry := range data.Entries {
+		if entry.URL == rawURL {
+			delete(data.Entries, key)
+			removed = true
+		}
+	}
+
+	if !removed {
+		return nil
+	}
+
+	return c.write(data)
+}
+
+// Purge removes entries (only expired ones when expiredOnly is set) and
+// returns how many were removed.
+func (c *Cache) Purge(expiredOnly bool) (int, error) {
+	data, err := c.read()
+	if err != nil {
+		return 0, err
+	}
+
+	now := c.now()
+	removed := 0
+	for key, entry := range data.Entries {
+		if expiredOnly && !entry.Expired(now) {
+			continue
+		}
+
+		delete(data.Entries, key)
+		removed++
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, c.write(data)
+}
+
+func (c *Cache) read() (cacheFile, error) {
+	data := cacheFile{Entries: make(map[string]CacheEntry)}
+
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+
+		return data, fmt.Errorf("read upload cache: %w", err)
+	}
+
+	if err = json.Unmarshal(b, &data); err != nil {
+		return data, fmt.Errorf("parse upload cache %s: %w", c.path, err)
+	}
+
+	if data.Entries == nil {
+		data.Entries = make(map[string]CacheEntry)
+	}
+
+	return data, nil
+}
+
+func (c *Cache) write(data cacheFile) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("ensure cache dir: %w", err)
+	}
+
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode upload cache: %w", err)
+	}
+
+	b = append(b, '\n')
+
+	tmp := c.path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o600); err != nil {
+		return fmt.Errorf("write upload cache: %w", err)
+	}
+
+	if err = os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("commit upload cache: %w", err)
+	}
+
+	return nil
+}
+
+// reachable fetches the first byte of rawURL the way the URL check does. A
+// ranged GET is used rather than HEAD because presigned URLs are only valid
+// for GET.
+func (c *Cache) reachable(ctx context.Context, rawURL string) bool {
+	ctx, cancel := context.WithTimeout(ctx, cacheProbeTimeout)
+	defer cancel()
+
+	verifier := &Verifier{HTTPClient: c.HTTPClient}
+	_, err := verifier.probe(ctx, http.MethodGet, rawURL)
+
+	return err == nil
+}
+
+func cacheKey(backend, identity, sum string) string {
+	if identity != "" {
+		backend += "@" + identity
+	}
+
+	return backend + ":" + sum
+}
+
+type sourceKey struct{}
+
+// WithSource returns a context that tells the upload cache which file the
+// user gave when the one uploaded is a converted copy of it, so the cache
+// does not record a temp path that is removed after the run.
+func WithSource(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, path)
+}
+
+// sourceOf returns the file set with WithSource, or path.
+func sourceOf(ctx context.Context, path string) string {
+	if source, _ := ctx.Value(sourceKey{}).(string); source != "" {
+		return source
+	}
+
+	return path
+}
+
+// WithCache wraps u so files already hosted on the same backend are reused
+// while their URL is valid. Uploads without a known expiry are not cached.
+func WithCache(u Uploader, cache *Cache) Uploader {
+	return &cachedUploader{Uploader: u, cache: cache}
+}
+
+type cachedUploader struct {
+	Uploader
+	cache *Cache
+}
+
+func (u *cachedUploader) Upload(ctx context.Context, path string) (Hosted, error) {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	identity := identityOf(u.Uploader)
+	entry, ok, err := u.cache.Lookup(ctx, u.Name(), identity, sum)
+	if err != nil {
+		slog.Warn("upload cache unavailable", "err", err)
+	}
+
+	if ok {
+		slog.Debug("reusing cached upload", "file", path, "url", entry.URL, "expires_at", entry.ExpiresAt)
+		return Hosted{
+			URL:           entry.URL,
+			DeleteHandle:  entry.DeleteHandle,
+			DeleteCommand: entry.DeleteCommand,
+			ExpiresAt:     entry.ExpiresAt,
+		}, nil
+	}
+
+	hosted, err := u.Uploader.Upload(ctx, path)
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	if hosted.ExpiresAt.IsZero() {
+		return hosted, nil
+	}
+
+	err = u.cache.Store(CacheEntry{
+		Backend:       u.Name(),
+		Identity:      identity,
+		SHA256:        sum,
+		File:          sourceOf(ctx, path),
+		URL:           hosted.URL,
+		DeleteHandle:  hosted.DeleteHandle,
+		DeleteCommand: hosted.DeleteCommand,
+		CreatedAt:     u.cache.now(),
+		ExpiresAt:     hosted.ExpiresAt,
+	})
+	if err != nil {
+		slog.Warn("update upload cache", "err", err)
+	}
+
+	return hosted, nil
+}
+
+// Delete removes the hosted copy through the wrapped backend and forgets it,
+// so later runs do not reuse a URL that no longer works. It is a no-op when
+// the backend cannot delete.
+func (u *cachedUploader) Delete(ctx context.Context, hosted Hosted) error {
+	deleter, ok := u.Uploader.(Deleter)
+	if !ok {
+		return nil
+	}
+
+	if err := u.cache.Remove(hosted.URL); err != nil {
+		slog.Warn("update upload cache", "err", err)
+	}
+
+	return deleter.Delete(ctx, hosted)
+}
+
+func fileSHA256(path string) (string, error) {
+	// #nosec G304 -- path is user-provided
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
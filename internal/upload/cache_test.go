@@ -0,0 +1,248 @@
+package upload
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeUploader struct {
+	calls     int
+	url       string
+	expiresAt time.Time
+	identity  string
+}
+
+func (f *fakeUploader) Name() string {
+	return "fake"
+}
+
+func (f *fakeUploader) Identity() string {
+	return f.identity
+}
+
+func (f *fakeUploader) Upload(_ context.Context, _ string) (Hosted, error) {
+	f.calls++
+	return Hosted{URL: f.url, DeleteHandle: "handle", ExpiresAt: f.expiresAt}, nil
+}
+
+func newTestCache(t *testing.T, now time.Time) *Cache {
+	t.Helper()
+
+	cache := NewCache(filepath.Join(t.TempDir(), "cache", "uploads.json"))
+	cache.now = func() time.Time { return now }
+	return cache
+}
+
+func TestCachedUploaderReusesReachableURL(t *testing.T) {
+	status := http.StatusPartialContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "bytes=0-0" {
+			t.Fatalf("expected ranged probe, got %q", r.Header.Get("Range"))
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now)
+	inner := &fakeUploader{url: server.URL + "/file.jpg", expiresAt: now.Add(time.Hour)}
+	uploader := WithCache(inner, cache)
+
+	path := writeTempFile(t, "photo.jpg")
+	for range 2 {
+		hosted, err := uploader.Upload(context.Background(), path)
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		if hosted.URL != inner.url || hosted.DeleteHandle != "handle" || !hosted.ExpiresAt.Equal(inner.expiresAt) {
+			t.Fatalf("unexpected hosted: %#v", hosted)
+		}
+	}
+
+	if inner.calls != 1 {
+		t.Fatalf("expected one real upload, got %d", inner.calls)
+	}
+
+	// Same content under another name is still a hit.
+	copyPath := filepath.Join(t.TempDir(), "copy.jpg")
+	if err := os.WriteFile(copyPath, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	if _, err := uploader.Upload(context.Background(), copyPath); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected content-addressed hit, got %d uploads", inner.calls)
+	}
+
+	// Unreachable URLs are uploaded again.
+	status = http.StatusNotFound
+	if _, err := uploader.Upload(context.Background(), path); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected re-upload for unreachable url, got %d uploads", inner.calls)
+	}
+}
+
+func TestCachedUploaderKeepsIdentitiesApart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now)
+	bucketA := &fakeUploader{url: server.URL + "/a/file.jpg", expiresAt: now.Add(time.Hour), identity: "https://s3.example.com/a/"}
+	bucketB := &fakeUploader{url: server.URL + "/b/file.jpg", expiresAt: now.Add(time.Hour), identity: "https://s3.example.com/b/"}
+
+	path := writeTempFile(t, "photo.jpg")
+	if _, err := WithCache(bucketA, cache).Upload(context.Background(), path); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	hosted, err := WithCache(bucketB, cache).Upload(context.Background(), path)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if bucketB.calls != 1 || hosted.URL != bucketB.url {
+		t.Fatalf("expected a fresh upload to the other bucket, got %d uploads and %s", bucketB.calls, hosted.URL)
+	}
+}
+
+func TestCachedUploaderSkipsExpiringEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now)
+	inner := &fakeUploader{url: server.URL, expiresAt: now.Add(10 * time.Minute)}
+	uploader := WithCache(inner, cache)
+
+	path := writeTempFile(t, "photo.jpg")
+	for range 2 {
+		if _, err := uploader.Upload(context.Background(), path); err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+	}
+
+	if inner.calls != 2 {
+		t.Fatalf("expected entries close to expiry to be ignored, got %d uploads", inner.calls)
+	}
+}
+
+func TestCachedUploaderIgnoresUnknownExpiry(t *testing.T) {
+	cache := newTestCache(t, time.Now())
+	uploader := WithCache(&fakeUploader{url: "https://example.com/file.jpg"}, cache)
+
+	if _, err := uploader.Upload(context.Background(), writeTempFile(t, "photo.jpg")); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected nothing cached, got %d entries", len(entries))
+	}
+}
+
+func TestCachedUploaderRecordsSourceFile(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now)
+	uploader := WithCache(&fakeUploader{url: "https://example.com/file.jpg", expiresAt: now.Add(time.Hour)}, cache)
+
+	converted := writeTempFile(t, "converted.jpg")
+	ctx := WithSource(context.Background(), "/photos/original.png")
+	if _, err := uploader.Upload(ctx, converted); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].File != "/photos/original.png" {
+		t.Fatalf("expected the source file recorded, got %#v", entries)
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now.Add(-2*time.Hour))
+
+	for i, expiresAt := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour)} {
+		err := cache.Store(CacheEntry{
+			Backend:   "fake",
+			SHA256:    string(rune('a' + i)),
+			URL:       "https://example.com",
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+
+	cache.now = func() time.Time { return now }
+
+	removed, err := cache.Purge(true)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one expired entry purged, got %d (%v)", removed, err)
+	}
+
+	entries, err := cache.Entries()
+	if err != nil || len(entries) != 1 || entries[0].SHA256 != "b" {
+		t.Fatalf("unexpected entries after purge: %#v (%v)", entries, err)
+	}
+
+	removed, err = cache.Purge(false)
+	if err != nil || removed != 1 {
+		t.Fatalf("expected remaining entry purged, got %d (%v)", removed, err)
+	}
+}
+
+type fakeDeleter struct {
+	fakeUploader
+	deleted []Hosted
+}
+
+func (f *fakeDeleter) Delete(_ context.Context, hosted Hosted) error {
+	f.deleted = append(f.deleted, hosted)
+	return nil
+}
+
+func TestCachedUploaderDeleteForgetsEntry(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	cache := newTestCache(t, now)
+	inner := &fakeDeleter{fakeUploader: fakeUploader{url: "https://cdn.example.com/file.jpg", expiresAt: now.Add(time.Hour)}}
+	uploader := WithCache(inner, cache)
+
+	hosted, err := uploader.Upload(context.Background(), writeTempFile(t, "photo.jpg"))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if err = uploader.(Deleter).Delete(context.Background(), hosted); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if len(inner.deleted) != 1 || inner.deleted[0].DeleteHandle != "handle" {
+		t.Fatalf("expected backend delete, got %#v", inner.deleted)
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected deleted upload to leave the cache, got %#v", entries)
+	}
+}
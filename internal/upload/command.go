@@ -0,0 +1,156 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// BackendCommand runs the executable configured as the upload command.
+	BackendCommand = "command"
+	// CommandPrefix is the name prefix for uploaders found on PATH:
+	// --uploader foo runs poster-uploader-foo.
+	CommandPrefix = "poster-uploader-"
+)
+
+// Command hosts files with an external executable. poster runs it with the
+// absolute file path as its only argument and reads one JSON object from
+// stdout:
+//
+//	{"url": "https://...", "expires_at": "2024-01-02T15:04:05Z", "delete_command": ["rclone", "deletefile", "remote:path"]}
+//
+// url is required and must be HTTPS. expires_at (RFC 3339) and
+// delete_command (an argv list) are optional. The executable's stderr is
+// passed through, and a non-zero exit status fails the upload.
+type Command struct {
+	name string
+	Path string
+}
+
+// NewCommand returns an uploader that runs the executable at path.
+func NewCommand(path string) (*Command, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: missing upload command", ErrCommandConfig)
+	}
+
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCommandConfig, err)
+	}
+
+	return &Command{name: BackendCommand, Path: resolved}, nil
+}
+
+// LookupCommand finds poster-uploader-<name> on PATH. It reports false when
+// there is no such executable.
+func LookupCommand(name string) (*Command, bool) {
+	path, err := exec.LookPath(CommandPrefix + name)
+	if err != nil {
+		return nil, false
+	}
+
+	return &Command{name: name, Path: path}, true
+}
+
+func (c *Command) Name() string {
+	return c.name
+}
+
+// Identity is the resolved executable, so two commands sharing a name (for
+// example in different profiles) do not share cache entries.
+func (c *Command) Identity() string {
+	return c.Path
+}
+
+type commandResult struct {
+	URL           string   `json:"url"`
+	ExpiresAt     string   `json:"expires_at"`
+	DeleteCommand []string `json:"delete_command"`
+}
+
+func (c *Command) Upload(ctx context.Context, path string) (Hosted, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Hosted{}, fmt.Errorf("resolve file path: %w", err)
+	}
+
+	if _, err = os.Stat(abs); err != nil {
+		return Hosted{}, fmt.Errorf("open file: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	// #nosec G204 -- the executable is chosen by the user
+	cmd := exec.CommandContext(ctx, c.Path, abs)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err = cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return Hosted{}, fmt.Errorf("%w: %s exited with status %d", ErrUploadFailed, filepath.Base(c.Path), exitErr.ExitCode())
+		}
+
+		return Hosted{}, fmt.Errorf("run %s: %w", filepath.Base(c.Path), err)
+	}
+
+	var result commandResult
+	if err = json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &result); err != nil {
+		return Hosted{}, fmt.Errorf("parse %s output: %w", filepath.Base(c.Path), err)
+	}
+
+	if result.URL == "" {
+		return Hosted{}, ErrUploadMissingURL
+	}
+
+	parsedURL, err := url.Parse(result.URL)
+	if err != nil {
+		return Hosted{}, fmt.Errorf("%w: %w", ErrUploadInvalidURL, err)
+	}
+
+	if parsedURL.Scheme != "https" {
+		return Hosted{}, fmt.Errorf("%w: %s", ErrInvalidURLScheme, parsedURL.Scheme)
+	}
+
+	hosted := Hosted{URL: result.URL, DeleteCommand: result.DeleteCommand}
+	if result.ExpiresAt != "" {
+		hosted.ExpiresAt, err = time.Parse(time.RFC3339, result.ExpiresAt)
+		if err != nil {
+			return Hosted{}, fmt.Errorf("parse %s expires_at: %w", filepath.Base(c.Path), err)
+		}
+	}
+
+	return hosted, nil
+}
+
+// Delete runs hosted.DeleteCommand, if the upload command returned one. Its
+// output goes to stderr so stdout stays machine-readable.
+func (c *Command) Delete(ctx context.Context, hosted Hosted) error {
+	if len(hosted.DeleteCommand) == 0 {
+		return nil
+	}
+
+	name := filepath.Base(hosted.DeleteCommand[0])
+	// #nosec G204 -- the delete command comes from the user's upload command
+	cmd := exec.CommandContext(ctx, hosted.DeleteCommand[0], hosted.DeleteCommand[1:]...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%w: %s exited with status %d", ErrDeleteFailed, name, exitErr.ExitCode())
+		}
+
+		return fmt.Errorf("run %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o700); err != nil { //nolint:gosec // test script must be executable
+		t.Fatalf("write script: %v", err)
+	}
+
+	return path
+}
+
+func TestCommandUpload(t *testing.T) {
+	dir := t.TempDir()
+	script := writeScript(t, dir, "upload.sh", `
+echo "uploading $1" >&2
+name=$(basename "$1")
+printf '{"url":"https://cdn.example.com/%s","expires_at":"2026-01-02T15:04:05Z","delete_command":["rm-remote","%s"]}\n' "$name" "$name"
+`)
+
+	uploader, err := NewCommand(script)
+	if err != nil {
+		t.Fatalf("new command: %v", err)
+	}
+
+	if uploader.Name() != BackendCommand {
+		t.Fatalf("unexpected name: %s", uploader.Name())
+	}
+
+	hosted, err := uploader.Upload(context.Background(), writeTempFile(t, "photo.jpg"))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if hosted.URL != "https://cdn.example.com/photo.jpg" {
+		t.Fatalf("unexpected url: %s", hosted.URL)
+	}
+	if !hosted.ExpiresAt.Equal(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Fatalf("unexpected expiry: %s", hosted.ExpiresAt)
+	}
+	if !slices.Equal(hosted.DeleteCommand, []string{"rm-remote", "photo.jpg"}) {
+		t.Fatalf("unexpected delete command: %v", hosted.DeleteCommand)
+	}
+}
+
+func TestCommandUploadFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"exit status", "exit 3\n", ErrUploadFailed},
+		{"missing url", "echo '{}'\n", ErrUploadMissingURL},
+		{"http url", `echo '{"url":"http://cdn.example.com/x"}'` + "\n", ErrInvalidURLScheme},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			uploader, err := NewCommand(writeScript(t, t.TempDir(), "upload.sh", tc.body))
+			if err != nil {
+				t.Fatalf("new command: %v", err)
+			}
+
+			_, err = uploader.Upload(context.Background(), writeTempFile(t, "photo.jpg"))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLookupCommandOnPath(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, CommandPrefix+"rclone", `echo '{"url":"https://r2.example.com/x"}'`+"\n")
+	t.Setenv("PATH", dir)
+
+	uploader, ok := LookupCommand("rclone")
+	if !ok {
+		t.Fatal("expected poster-uploader-rclone to be found")
+	}
+
+	if uploader.Name() != "rclone" {
+		t.Fatalf("unexpected name: %s", uploader.Name())
+	}
+
+	if _, ok = LookupCommand("missing"); ok {
+		t.Fatal("expected missing uploader not to be found")
+	}
+}
+
+func TestNewCommandRequiresExecutable(t *testing.T) {
+	if _, err := NewCommand(""); !errors.Is(err, ErrCommandConfig) {
+		t.Fatalf("expected ErrCommandConfig, got %v", err)
+	}
+
+	if _, err := NewCommand(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, ErrCommandConfig) {
+		t.Fatalf("expected ErrCommandConfig, got %v", err)
+	}
+}
+
+func TestCommandDelete(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "deleted")
+	script := writeScript(t, dir, "delete.sh", `echo "$1" > "$2"`+"\n")
+	failing := writeScript(t, dir, "fail.sh", "exit 4\n")
+
+	uploader := &Command{name: BackendCommand, Path: script}
+	if err := uploader.Delete(context.Background(), Hosted{}); err != nil {
+		t.Fatalf("delete without command: %v", err)
+	}
+
+	if err := uploader.Delete(context.Background(), Hosted{DeleteCommand: []string{script, "photo.jpg", marker}}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	got, err := os.ReadFile(marker) // #nosec G304 -- test file
+	if err != nil || string(got) != "photo.jpg\n" {
+		t.Fatalf("delete command not run: %q, %v", got, err)
+	}
+
+	err = uploader.Delete(context.Background(), Hosted{DeleteCommand: []string{failing}})
+	if !errors.Is(err, ErrDeleteFailed) {
+		t.Fatalf("expected ErrDeleteFailed, got %v", err)
+	}
+}
@@ -7,4 +7,14 @@ var (
 	ErrUploadMissingURL = errors.New("upload failed: missing url")
 	ErrInvalidURLScheme = errors.New("invalid url scheme")
 	ErrUploadInvalidURL = errors.New("invalid url")
+	ErrS3Config         = errors.New("invalid s3 uploader config")
+	ErrServeConfig      = errors.New("invalid serve uploader config")
+	ErrCommandConfig    = errors.New("invalid upload command")
+	ErrServerStopped    = errors.New("file server already stopped")
+	ErrDeleteFailed     = errors.New("delete failed")
+	ErrURLUnreachable   = errors.New("media url not reachable")
+	ErrURLStatus        = errors.New("media url returned an error")
+	ErrURLRedirect      = errors.New("media url redirects")
+	ErrURLContentType   = errors.New("media url has an unsupported content type")
+	ErrURLTooLarge      = errors.New("media file too large")
 )
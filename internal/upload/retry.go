@@ -0,0 +1,136 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy controls how often an upload to one host is retried. Only
+// network errors and the listed HTTP statuses are retried; anything else
+// fails straight away. Zero fields fall back to DefaultRetryPolicy.
+type RetryPolicy struct {
+	// Attempts is the total number of tries per host, including the first.
+	Attempts int
+	// BaseDelay is the backoff before the first retry. It doubles on every
+	// further retry, up to MaxDelay, and the actual wait is jittered.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// RetryableStatuses are the HTTP status codes worth retrying.
+	RetryableStatuses []int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: time.Second,
+	MaxDelay:  30 * time.Second,
+	RetryableStatuses: []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// StatusError is returned when a hosting backend answers with a non-2xx
+// status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s: status %d", ErrUploadFailed, e.StatusCode)
+	}
+
+	return fmt.Sprintf("%s: status %d: %s", ErrUploadFailed, e.StatusCode, e.Message)
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrUploadFailed
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultRetryPolicy.Attempts
+	}
+
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+
+	if p.MaxDelay < p.BaseDelay {
+		p.MaxDelay = p.BaseDelay
+	}
+
+	if p.RetryableStatuses == nil {
+		p.RetryableStatuses = DefaultRetryPolicy.RetryableStatuses
+	}
+
+	return p
+}
+
+// do runs fn until it succeeds, fails with an error that is not worth
+// retrying, or the attempts run out.
+func (p RetryPolicy) do(ctx context.Context, host string, fn func() error) error {
+	p = p.withDefaults()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || attempt >= p.Attempts || !p.retryable(ctx, err) {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		slog.Warn("upload failed, retrying", "host", host, "attempt", attempt, "wait", wait.Round(time.Millisecond), "err", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("upload canceled: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (p RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return slices.Contains(p.RetryableStatuses, statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns the wait before retry number attempt (1-based): a random
+// duration between half and all of the capped exponential delay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, p.MaxDelay)
+
+	half := delay / 2
+	return half + rand.N(delay-half+1) // #nosec G404 -- jitter does not need crypto randomness
+}
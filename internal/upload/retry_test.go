@@ -0,0 +1,174 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetry = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: time.Millisecond,
+	MaxDelay:  2 * time.Millisecond,
+}
+
+func writeTempFile(t *testing.T, name string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	return path
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}.withDefaults()
+
+	for _, tc := range []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{6, time.Second},
+	} {
+		for range 20 {
+			got := policy.backoff(tc.attempt)
+			if got < tc.max/2 || got > tc.max {
+				t.Fatalf("attempt %d: backoff %s outside [%s, %s]", tc.attempt, got, tc.max/2, tc.max)
+			}
+		}
+	}
+}
+
+func TestUguuRetriesRetryableStatus(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"success":true,"files":[{"url":"https://uguu.se/file.png"}]}`))
+	}))
+	defer server.Close()
+
+	uguu := &Uguu{URL: server.URL, HTTPClient: server.Client(), Retry: fastRetry}
+
+	hosted, err := uguu.Upload(context.Background(), writeTempFile(t, "file.png"))
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if hosted.URL != "https://uguu.se/file.png" || calls.Load() != 3 {
+		t.Fatalf("unexpected result: %s after %d calls", hosted.URL, calls.Load())
+	}
+}
+
+func TestUguuDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer server.Close()
+
+	uguu := &Uguu{URL: server.URL, HTTPClient: server.Client(), Retry: fastRetry}
+
+	_, err := uguu.Upload(context.Background(), writeTempFile(t, "file.png"))
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 status error, got %v", err)
+	}
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestUguuFailsOverToFallbackHost(t *testing.T) {
+	var primaryCalls atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer primary.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"error":"disk full"}`))
+	}))
+	defer broken.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"files":[{"url":"https://mirror.example.com/file.png"}]}`))
+	}))
+	defer mirror.Close()
+
+	uguu := &Uguu{
+		URL:        primary.URL,
+		Fallbacks:  []string{broken.URL, mirror.URL},
+		HTTPClient: http.DefaultClient,
+		Retry:      fastRetry,
+	}
+
+	hosted, err := uguu.Upload(context.Background(), writeTempFile(t, "file.png"))
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if hosted.URL != "https://mirror.example.com/file.png" {
+		t.Fatalf("unexpected url: %s", hosted.URL)
+	}
+
+	if primaryCalls.Load() != int32(fastRetry.Attempts) {
+		t.Fatalf("expected %d attempts on primary, got %d", fastRetry.Attempts, primaryCalls.Load())
+	}
+}
+
+func TestUguuReportsAllHostFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	uguu := &Uguu{
+		URL:        server.URL,
+		Fallbacks:  []string{server.URL + "/mirror"},
+		HTTPClient: server.Client(),
+		Retry:      RetryPolicy{Attempts: 1},
+	}
+
+	_, err := uguu.Upload(context.Background(), writeTempFile(t, "file.png"))
+	if !errors.Is(err, ErrUploadFailed) {
+		t.Fatalf("expected ErrUploadFailed, got %v", err)
+	}
+}
+
+func TestUguuMissingFileSkipsFallbacks(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	uguu := &Uguu{URL: server.URL, Fallbacks: []string{server.URL}, HTTPClient: server.Client(), Retry: fastRetry}
+
+	_, err := uguu.Upload(context.Background(), filepath.Join(t.TempDir(), "missing.png"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+
+	if calls.Load() != 0 {
+		t.Fatalf("expected no requests, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,305 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mahmoudashraf93/poster/internal/progress"
+)
+
+const BackendS3 = "s3"
+
+// DefaultS3URLExpiry is how long presigned GET URLs stay valid by default.
+const DefaultS3URLExpiry = time.Hour
+
+// S3Config configures an S3-compatible bucket. Endpoint is optional; when
+// empty, AWS S3 is used with virtual-hosted style URLs. Custom endpoints
+// (MinIO and similar) use path-style URLs.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	URLExpiry       time.Duration
+}
+
+// S3 uploads to an S3-compatible bucket and hands out presigned GET URLs, so
+// objects never need to be public.
+type S3 struct {
+	cfg        S3Config
+	HTTPClient *http.Client
+	Retry      RetryPolicy
+	now        func() time.Time
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	missing := make([]string, 0, 4)
+	for _, field := range []struct{ name, value string }{
+		{"bucket", cfg.Bucket},
+		{"region", cfg.Region},
+		{"access key id", cfg.AccessKeyID},
+		{"secret access key", cfg.SecretAccessKey},
+	} {
+		if field.value == "" {
+			missing = append(missing, field.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", ErrS3Config, strings.Join(missing, ", "))
+	}
+
+	// Meta only fetches https URLs, and presigned URLs share the endpoint's
+	// scheme, so catch a plain http endpoint before anything is uploaded.
+	if cfg.Endpoint != "" {
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid endpoint: %w", ErrS3Config, err)
+		}
+
+		if endpoint.Host == "" {
+			return nil, fmt.Errorf("%w: endpoint must include scheme and host", ErrS3Config)
+		}
+
+		if endpoint.Scheme != "https" {
+			return nil, fmt.Errorf("%w: endpoint must be https: %w: %s", ErrS3Config, ErrInvalidURLScheme, endpoint.Scheme)
+		}
+	}
+
+	if cfg.URLExpiry <= 0 {
+		cfg.URLExpiry = DefaultS3URLExpiry
+	}
+
+	// SigV4 presigned URLs are valid for at most seven days.
+	if cfg.URLExpiry > 7*24*time.Hour {
+		return nil, fmt.Errorf("%w: url expiry must be at most 168h", ErrS3Config)
+	}
+
+	return &S3{
+		cfg:        cfg,
+		HTTPClient: http.DefaultClient,
+		Retry:      DefaultRetryPolicy,
+		now:        time.Now,
+	}, nil
+}
+
+func (s *S3) Name() string {
+	return BackendS3
+}
+
+// Identity names the endpoint, bucket and prefix objects are written to.
+func (s *S3) Identity() string {
+	endpoint := s.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "aws:" + s.cfg.Region
+	}
+
+	return strings.TrimSuffix(endpoint, "/") + "/" + s.cfg.Bucket + "/" + strings.Trim(s.cfg.Prefix, "/")
+}
+
+func (s *S3) Upload(ctx context.Context, filepath string) (Hosted, error) {
+	key, err := s.objectKey(filepath)
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	err = s.Retry.do(ctx, objectURL.Host, func() error {
+		return s.put(ctx, objectURL, filepath)
+	})
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	now := s.now()
+	signed := s.credentials().presign(http.MethodGet, objectURL, s.cfg.URLExpiry, now)
+
+	return Hosted{URL: signed.String(), DeleteHandle: key, ExpiresAt: now.Add(s.cfg.URLExpiry)}, nil
+}
+
+// Delete removes the object named by hosted.DeleteHandle. A missing object
+// counts as deleted.
+func (s *S3) Delete(ctx context.Context, hosted Hosted) error {
+	if hosted.DeleteHandle == "" {
+		return nil
+	}
+
+	objectURL, err := s.objectURL(hosted.DeleteHandle)
+	if err != nil {
+		return err
+	}
+
+	return s.Retry.do(ctx, objectURL.Host, func() error {
+		return s.delete(ctx, objectURL)
+	})
+}
+
+func (s *S3) delete(ctx context.Context, objectURL *url.URL) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, objectURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	s.credentials().signRequest(req, emptyPayload, s.now())
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{StatusCode: resp.StatusCode, Message: s3ErrorMessage(payload)}
+	}
+
+	return nil
+}
+
+func (s *S3) put(ctx context.Context, objectURL *url.URL, filepath string) error {
+	// #nosec G304 -- filepath is user-provided
+	file, err := os.Open(filepath)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), progress.Track(ctx, file, info.Size()))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.ContentLength = info.Size()
+	if contentType := contentTypeFor(filepath); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.credentials().signRequest(req, unsignedPayload, s.now())
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload request: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{StatusCode: resp.StatusCode, Message: s3ErrorMessage(payload)}
+	}
+
+	return nil
+}
+
+func (s *S3) credentials() sigV4Credentials {
+	return sigV4Credentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+		Region:          s.cfg.Region,
+	}
+}
+
+// objectKey returns prefix/<random>/<base name>, so uploads never collide
+// and URLs cannot be guessed from the file name alone.
+func (s *S3) objectKey(filepath string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generate object key: %w", err)
+	}
+
+	return path.Join(strings.Trim(s.cfg.Prefix, "/"), hex.EncodeToString(random), baseName(filepath)), nil
+}
+
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	if s.cfg.Endpoint == "" {
+		u := &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", s.cfg.Bucket, s.cfg.Region),
+		}
+		setObjectPath(u, "/"+key)
+
+		return u, nil
+	}
+
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUploadInvalidURL, err)
+	}
+
+	if endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint must include scheme and host", ErrS3Config)
+	}
+
+	setObjectPath(endpoint, strings.TrimSuffix(endpoint.Path, "/")+"/"+s.cfg.Bucket+"/"+key)
+	endpoint.RawQuery = ""
+
+	return endpoint, nil
+}
+
+// setObjectPath sets the path with the same escaping SigV4 uses for the
+// canonical URI, so the signed and requested paths always match.
+func setObjectPath(u *url.URL, p string) {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	u.Path = p
+	u.RawPath = strings.Join(segments, "/")
+}
+
+func baseName(p string) string {
+	name := filepath.Base(p)
+	if name == "." || name == string(filepath.Separator) {
+		return "file"
+	}
+
+	return name
+}
+
+// s3ErrorMessage extracts the message from an S3 XML error body.
+func s3ErrorMessage(payload []byte) string {
+	var parsed struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(payload, &parsed); err != nil || parsed.Message == "" {
+		return strings.TrimSpace(string(payload))
+	}
+
+	return fmt.Sprintf("%s: %s", parsed.Code, parsed.Message)
+}
@@ -0,0 +1,181 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestS3(t *testing.T, server *httptest.Server) *S3 {
+	t.Helper()
+
+	s3, err := NewS3(S3Config{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "media",
+		Prefix:          "/poster/",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		URLExpiry:       15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("new s3: %v", err)
+	}
+
+	s3.HTTPClient = server.Client()
+
+	return s3
+}
+
+func TestS3UploadPresignsURL(t *testing.T) {
+	var putPath string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Fatalf("expected PUT, got %s", r.Method)
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Fatalf("unexpected authorization: %s", r.Header.Get("Authorization"))
+		}
+
+		if r.Header.Get("X-Amz-Content-Sha256") != unsignedPayload {
+			t.Fatalf("unexpected payload hash: %s", r.Header.Get("X-Amz-Content-Sha256"))
+		}
+
+		if r.Header.Get("Content-Type") != "video/mp4" {
+			t.Fatalf("unexpected content type: %s", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "data" {
+			t.Fatalf("unexpected body: %q", body)
+		}
+
+		putPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "my clip.mp4")
+	if err := os.WriteFile(filePath, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	hosted, err := newTestS3(t, server).Upload(context.Background(), filePath)
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if !strings.HasPrefix(putPath, "/media/poster/") || !strings.HasSuffix(putPath, "/my clip.mp4") {
+		t.Fatalf("unexpected object path: %s", putPath)
+	}
+
+	if "/media/"+hosted.DeleteHandle != putPath {
+		t.Fatalf("unexpected delete handle: %s", hosted.DeleteHandle)
+	}
+
+	parsed, err := url.Parse(hosted.URL)
+	if err != nil {
+		t.Fatalf("parse url: %v", err)
+	}
+
+	if parsed.Scheme != "https" || parsed.Path != putPath {
+		t.Fatalf("unexpected url: %s", hosted.URL)
+	}
+
+	if parsed.Query().Get("X-Amz-Expires") != "900" || parsed.Query().Get("X-Amz-Signature") == "" {
+		t.Fatalf("url is not presigned: %s", hosted.URL)
+	}
+}
+
+func TestS3UploadError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "file.jpg")
+	if err := os.WriteFile(filePath, []byte("data"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	_, err := newTestS3(t, server).Upload(context.Background(), filePath)
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied: Access Denied") {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+}
+
+func TestS3Delete(t *testing.T) {
+	var deleted []string
+	status := http.StatusNoContent
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Fatalf("expected DELETE, got %s", r.Method)
+		}
+
+		if r.Header.Get("X-Amz-Content-Sha256") != emptyPayload {
+			t.Fatalf("unexpected payload hash: %s", r.Header.Get("X-Amz-Content-Sha256"))
+		}
+
+		deleted = append(deleted, r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s3 := newTestS3(t, server)
+	if err := s3.Delete(context.Background(), Hosted{DeleteHandle: "poster/abc/clip.mp4"}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// Already gone counts as deleted.
+	status = http.StatusNotFound
+	if err := s3.Delete(context.Background(), Hosted{DeleteHandle: "poster/abc/clip.mp4"}); err != nil {
+		t.Fatalf("delete missing object: %v", err)
+	}
+
+	if err := s3.Delete(context.Background(), Hosted{}); err != nil {
+		t.Fatalf("delete without handle: %v", err)
+	}
+
+	if len(deleted) != 2 || deleted[0] != "/media/poster/abc/clip.mp4" {
+		t.Fatalf("unexpected deletes: %v", deleted)
+	}
+
+	status = http.StatusForbidden
+	if err := s3.Delete(context.Background(), Hosted{DeleteHandle: "poster/abc/clip.mp4"}); !errors.Is(err, ErrUploadFailed) {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestNewS3RequiresConfig(t *testing.T) {
+	_, err := NewS3(S3Config{Bucket: "media"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if !strings.Contains(err.Error(), "region, access key id, secret access key") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewS3RejectsPlainHTTPEndpoint(t *testing.T) {
+	_, err := NewS3(S3Config{
+		Endpoint:        "http://minio.local:9000",
+		Region:          "us-east-1",
+		Bucket:          "media",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	if !errors.Is(err, ErrS3Config) || !errors.Is(err, ErrInvalidURLScheme) {
+		t.Fatalf("expected scheme error, got %v", err)
+	}
+}
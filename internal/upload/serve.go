@@ -0,0 +1,248 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const BackendServe = "serve"
+
+const (
+	// DefaultServeListenAddr is where the built-in server listens when no
+	// address is configured. The reverse proxy or tunnel forwards to it.
+	DefaultServeListenAddr = "127.0.0.1:8080"
+	// DefaultServeTTL bounds how long a file is served if the caller never
+	// releases the server.
+	DefaultServeTTL = 30 * time.Minute
+)
+
+const serveShutdownTimeout = 5 * time.Second
+
+// ServeConfig configures the built-in file server. PublicBaseURL is the
+// HTTPS address at which a reverse proxy or tunnel exposes ListenAddr.
+type ServeConfig struct {
+	PublicBaseURL string
+	ListenAddr    string
+	TTL           time.Duration
+}
+
+// Server serves local files from an HTTP server inside poster, so nothing is
+// uploaded to a third party. Each file gets a random, unguessable path. The
+// server starts on the first Upload and stops on Close or when the TTL runs
+// out, whichever comes first.
+type Server struct {
+	cfg  ServeConfig
+	base *url.URL
+
+	mu      sync.Mutex
+	files   map[string]string // token -> local path
+	srv     *http.Server
+	ln      net.Listener
+	timer   *time.Timer
+	stopped bool
+}
+
+func NewServer(cfg ServeConfig) (*Server, error) {
+	if cfg.PublicBaseURL == "" {
+		return nil, fmt.Errorf("%w: missing public base url", ErrServeConfig)
+	}
+
+	base, err := url.Parse(cfg.PublicBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public base url: %w", ErrServeConfig, err)
+	}
+
+	if base.Scheme != "https" || base.Host == "" {
+		return nil, fmt.Errorf("%w: public base url must be an https url", ErrServeConfig)
+	}
+
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = DefaultServeListenAddr
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultServeTTL
+	}
+
+	return &Server{
+		cfg:   cfg,
+		base:  base,
+		files: make(map[string]string),
+	}, nil
+}
+
+func (s *Server) Name() string {
+	return BackendServe
+}
+
+func (s *Server) Upload(_ context.Context, path string) (Hosted, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Hosted{}, fmt.Errorf("open file: %w", err)
+	}
+
+	if info.IsDir() {
+		return Hosted{}, fmt.Errorf("open file: %s is a directory", path)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return Hosted{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return Hosted{}, ErrServerStopped
+	}
+
+	if err = s.start(); err != nil {
+		return Hosted{}, err
+	}
+
+	s.files[token] = path
+
+	publicURL := s.base.JoinPath(token, filepath.Base(path))
+	return Hosted{URL: publicURL.String(), DeleteHandle: token}, nil
+}
+
+// Delete stops serving the file handed out under hosted.DeleteHandle.
+func (s *Server) Delete(_ context.Context, hosted Hosted) error {
+	s.mu.Lock()
+	delete(s.files, hosted.DeleteHandle)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// start begins listening on the first call. Callers must hold s.mu.
+func (s *Server) start() error {
+	if s.srv != nil {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("serve listen: %w", err)
+	}
+
+	s.ln = ln
+	s.srv = &http.Server{
+		Handler:           http.HandlerFunc(s.handle),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if serveErr := s.srv.Serve(ln); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			slog.Warn("file server stopped", "err", serveErr)
+		}
+	}()
+
+	s.timer = time.AfterFunc(s.cfg.TTL, func() {
+		slog.Debug("file server ttl expired", "ttl", s.cfg.TTL)
+		_ = s.Close()
+	})
+
+	slog.Debug("file server started", "addr", ln.Addr().String(), "public_base_url", s.cfg.PublicBaseURL)
+	return nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Requests arrive as /<token>/<name>, possibly below the base URL path
+	// when the proxy does not strip it.
+	rest := strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(s.base.Path, "/"))
+	token, name, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+
+	s.mu.Lock()
+	path, ok := s.files[token]
+	s.mu.Unlock()
+
+	if !ok || name != filepath.Base(path) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// #nosec G304 -- path was registered by Upload
+	file, err := os.Open(path)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if contentType := contentTypeFor(path); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	slog.Debug("serving file", "path", path, "remote", r.RemoteAddr)
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+// Close stops serving. Files handed out by Upload are no longer reachable
+// afterwards. It is safe to call more than once.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.stopped = true
+	srv := s.srv
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		_ = srv.Close()
+		return fmt.Errorf("stop file server: %w", err)
+	}
+
+	slog.Debug("file server stopped")
+	return nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
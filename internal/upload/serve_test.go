@@ -0,0 +1,180 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, base string, ttl time.Duration) *Server {
+	t.Helper()
+
+	s, err := NewServer(ServeConfig{
+		PublicBaseURL: base,
+		ListenAddr:    "127.0.0.1:0",
+		TTL:           ttl,
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+
+	return s
+}
+
+// localURL maps a public URL onto the test listener.
+func localURL(t *testing.T, s *Server, publicURL string) string {
+	t.Helper()
+
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		t.Fatalf("parse url: %v", err)
+	}
+
+	u.Scheme = "http"
+	u.Host = s.ln.Addr().String()
+	return u.String()
+}
+
+func TestServerServesUntilClosed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my photo.jpg")
+	if err := os.WriteFile(path, []byte("jpeg-bytes"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	s := newTestServer(t, "https://media.example.com/poster/", time.Minute)
+
+	hosted, err := s.Upload(context.Background(), path)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if !strings.HasPrefix(hosted.URL, "https://media.example.com/poster/") {
+		t.Fatalf("unexpected url: %s", hosted.URL)
+	}
+	if !strings.HasSuffix(hosted.URL, "/my%20photo.jpg") {
+		t.Fatalf("expected escaped file name in url: %s", hosted.URL)
+	}
+	if len(hosted.DeleteHandle) != 48 {
+		t.Fatalf("expected 48-char token, got %q", hosted.DeleteHandle)
+	}
+
+	resp, err := http.Get(localURL(t, s, hosted.URL)) //nolint:noctx // test request
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "jpeg-bytes" {
+		t.Fatalf("unexpected response: %d %q", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "image/jpeg" {
+		t.Fatalf("unexpected content type: %s", got)
+	}
+
+	addr := s.ln.Addr().String()
+	if err = s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if _, err = http.Get("http://" + addr + "/"); err == nil { //nolint:noctx // test request
+		t.Fatalf("expected server to be stopped")
+	}
+
+	if _, err = s.Upload(context.Background(), path); !errors.Is(err, ErrServerStopped) {
+		t.Fatalf("expected ErrServerStopped, got %v", err)
+	}
+}
+
+func TestServerRejectsUnknownPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clip.mp4")
+	if err := os.WriteFile(path, []byte("mp4"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	s := newTestServer(t, "https://media.example.com", time.Minute)
+
+	hosted, err := s.Upload(context.Background(), path)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	local := localURL(t, s, hosted.URL)
+	for _, target := range []string{
+		strings.Replace(local, hosted.DeleteHandle, strings.Repeat("0", 48), 1),
+		strings.TrimSuffix(local, "clip.mp4") + "other.mp4",
+	} {
+		resp, getErr := http.Get(target) //nolint:noctx // test request
+		if getErr != nil {
+			t.Fatalf("get: %v", getErr)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", target, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, local, nil) //nolint:noctx // test request
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerStopsAfterTTL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(path, []byte("jpeg"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	s := newTestServer(t, "https://media.example.com", 50*time.Millisecond)
+
+	if _, err := s.Upload(context.Background(), path); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s.mu.Lock()
+		stopped := s.stopped
+		s.mu.Unlock()
+
+		if stopped {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("server still running after ttl")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNewServerRequiresHTTPSBaseURL(t *testing.T) {
+	for _, base := range []string{"", "http://media.example.com", "media.example.com"} {
+		if _, err := NewServer(ServeConfig{PublicBaseURL: base}); !errors.Is(err, ErrServeConfig) {
+			t.Fatalf("expected ErrServeConfig for %q, got %v", base, err)
+		}
+	}
+}
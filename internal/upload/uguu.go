@@ -4,53 +4,125 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
+
+	"github.com/mahmoudashraf93/poster/internal/progress"
 )
 
 var uguuUploadURL = "https://uguu.se/upload.php"
 
-func Upload(ctx context.Context, filepath string) (string, error) {
+// UguuRetention is how long uguu.se keeps uploaded files.
+const UguuRetention = 3 * time.Hour
+
+// Uguu uploads to uguu.se (or a compatible pomf instance). Files are public
+// to anyone with the URL and expire after a few hours.
+//
+// Each host is tried according to Retry. When URL keeps failing, the
+// Fallbacks (other pomf-compatible upload endpoints) are tried in order.
+type Uguu struct {
+	URL        string
+	Fallbacks  []string
+	HTTPClient *http.Client
+	Retry      RetryPolicy
+}
+
+func NewUguu() *Uguu {
+	return &Uguu{
+		URL:        uguuUploadURL,
+		HTTPClient: http.DefaultClient,
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+func (u *Uguu) Name() string {
+	return BackendUguu
+}
+
+func (u *Uguu) Upload(ctx context.Context, filepath string) (Hosted, error) {
+	hosts := append([]string{u.URL}, u.Fallbacks...)
+
+	var errs []error
+	for i, host := range hosts {
+		var hosted Hosted
+		err := u.Retry.do(ctx, host, func() error {
+			var uploadErr error
+			hosted, uploadErr = u.uploadTo(ctx, host, filepath)
+			return uploadErr
+		})
+		if err == nil {
+			return hosted, nil
+		}
+
+		// Local problems and cancellation would fail on every host.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) || ctx.Err() != nil || len(hosts) == 1 {
+			return Hosted{}, err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", host, err))
+		if i < len(hosts)-1 {
+			slog.Warn("upload host failed, trying next", "host", host, "next", hosts[i+1], "err", err)
+		}
+	}
+
+	return Hosted{}, fmt.Errorf("all upload hosts failed: %w", errors.Join(errs...))
+}
+
+func (u *Uguu) uploadTo(ctx context.Context, endpoint, filepath string) (Hosted, error) {
 	// #nosec G304 -- filepath is user-provided
 	file, err := os.Open(filepath)
 	if err != nil {
-		return "", fmt.Errorf("open file: %w", err)
+		return Hosted{}, fmt.Errorf("open file: %w", err)
 	}
 
 	defer func() {
 		_ = file.Close()
 	}()
 
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
-
-	part, err := writer.CreateFormFile("files[]", file.Name())
+	info, err := file.Stat()
 	if err != nil {
-		return "", fmt.Errorf("create form file: %w", err)
+		return Hosted{}, fmt.Errorf("stat file: %w", err)
 	}
 
-	if _, err = io.Copy(part, file); err != nil {
-		return "", fmt.Errorf("copy file: %w", err)
+	// Only the multipart framing is buffered; the file itself is streamed
+	// between the head and tail so large videos are never held in memory.
+	var head, tail bytes.Buffer
+	writer := multipart.NewWriter(&head)
+
+	if _, err = writer.CreateFormFile("files[]", file.Name()); err != nil {
+		return Hosted{}, fmt.Errorf("create form file: %w", err)
 	}
 
+	headLen := head.Len()
 	if err = writer.Close(); err != nil {
-		return "", fmt.Errorf("close writer: %w", err)
+		return Hosted{}, fmt.Errorf("close writer: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uguuUploadURL, &body)
+	tail.Write(head.Bytes()[headLen:])
+	head.Truncate(headLen)
+
+	body := io.MultiReader(&head, progress.Track(ctx, file, info.Size()), &tail)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return Hosted{}, fmt.Errorf("create request: %w", err)
 	}
 
+	req.ContentLength = int64(head.Len()) + info.Size() + int64(tail.Len())
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := u.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("upload request: %w", err)
+		return Hosted{}, fmt.Errorf("upload request: %w", err)
 	}
 
 	defer func() {
@@ -59,42 +131,42 @@ func Upload(ctx context.Context, filepath string) (string, error) {
 
 	payload, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return Hosted{}, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return "", fmt.Errorf("%w: status %d", ErrUploadFailed, resp.StatusCode)
+		return Hosted{}, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	var parsed uguuResponse
 	if err = json.Unmarshal(payload, &parsed); err != nil {
-		return "", fmt.Errorf("parse response: %w", err)
+		return Hosted{}, fmt.Errorf("parse response: %w", err)
 	}
 
 	if !parsed.Success {
 		if parsed.Error != "" {
-			return "", fmt.Errorf("%w: %s", ErrUploadFailed, parsed.Error)
+			return Hosted{}, fmt.Errorf("%w: %s", ErrUploadFailed, parsed.Error)
 		}
 
-		return "", ErrUploadFailed
+		return Hosted{}, ErrUploadFailed
 	}
 
 	if len(parsed.Files) == 0 || parsed.Files[0].URL == "" {
-		return "", ErrUploadMissingURL
+		return Hosted{}, ErrUploadMissingURL
 	}
 
 	publicURL := parsed.Files[0].URL
 
 	parsedURL, err := url.Parse(publicURL)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrUploadInvalidURL, err)
+		return Hosted{}, fmt.Errorf("%w: %w", ErrUploadInvalidURL, err)
 	}
 
 	if parsedURL.Scheme != "https" {
-		return "", fmt.Errorf("%w: %s", ErrInvalidURLScheme, parsedURL.Scheme)
+		return Hosted{}, fmt.Errorf("%w: %s", ErrInvalidURLScheme, parsedURL.Scheme)
 	}
 
-	return publicURL, nil
+	return Hosted{URL: publicURL, ExpiresAt: time.Now().Add(UguuRetention)}, nil
 }
 
 type uguuResponse struct {
@@ -1,12 +1,16 @@
 package upload
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/mahmoudashraf93/poster/internal/progress"
 )
 
 func TestUploadSuccess(t *testing.T) {
@@ -36,13 +40,70 @@ func TestUploadSuccess(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	url, err := Upload(context.Background(), filePath)
+	hosted, err := NewUguu().Upload(context.Background(), filePath)
 	if err != nil {
 		t.Fatalf("upload failed: %v", err)
 	}
 
-	if url != "https://uguu.se/file.png" {
-		t.Fatalf("unexpected url: %s", url)
+	if hosted.URL != "https://uguu.se/file.png" {
+		t.Fatalf("unexpected url: %s", hosted.URL)
+	}
+}
+
+func TestUguuUploadStreamsWithContentLength(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100_000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= int64(len(content)) {
+			t.Fatalf("expected known content length above file size, got %d", r.ContentLength)
+		}
+		if len(r.TransferEncoding) > 0 {
+			t.Fatalf("expected no chunked encoding, got %v", r.TransferEncoding)
+		}
+
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			t.Fatalf("parse multipart: %v", err)
+		}
+
+		file, _, err := r.FormFile("files[]")
+		if err != nil {
+			t.Fatalf("missing files[]: %v", err)
+		}
+
+		got, _ := io.ReadAll(file)
+		if !bytes.Equal(got, content) {
+			t.Fatalf("file content mismatch: got %d bytes", len(got))
+		}
+
+		_, _ = w.Write([]byte(`{"success":true,"files":[{"url":"https://uguu.se/big.mp4"}]}`))
+	}))
+	defer server.Close()
+
+	filePath := filepath.Join(t.TempDir(), "big.mp4")
+	if err := os.WriteFile(filePath, content, 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	var lastSent, lastTotal int64
+	calls := 0
+	ctx := progress.With(context.Background(), func(sent, total int64) {
+		if sent < lastSent {
+			t.Fatalf("progress went backwards: %d after %d", sent, lastSent)
+		}
+		lastSent, lastTotal = sent, total
+		calls++
+	})
+
+	uguu := &Uguu{URL: server.URL, HTTPClient: server.Client()}
+	if _, err := uguu.Upload(ctx, filePath); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+
+	if calls < 2 {
+		t.Fatalf("expected several progress callbacks, got %d", calls)
+	}
+	if lastSent != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Fatalf("unexpected final progress: %d/%d", lastSent, lastTotal)
 	}
 }
 
@@ -63,7 +124,7 @@ func TestUploadRejectsNonHTTPS(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	_, err := Upload(context.Background(), filePath)
+	_, err := NewUguu().Upload(context.Background(), filePath)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -86,7 +147,7 @@ func TestUploadHTTPError(t *testing.T) {
 		t.Fatalf("write temp file: %v", err)
 	}
 
-	_, err := Upload(context.Background(), filePath)
+	_, err := NewUguu().Upload(context.Background(), filePath)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -0,0 +1,77 @@
+package upload
+
+import (
+	"context"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mahmoudashraf93/poster/internal/media"
+)
+
+// Hosted describes a file placed on a hosting backend.
+type Hosted struct {
+	// URL is the public HTTPS URL Meta fetches the media from.
+	URL string
+	// DeleteHandle identifies the hosted copy for backends that can remove
+	// it again (for example a delete key or object key). Empty otherwise.
+	DeleteHandle string
+	// DeleteCommand is an argv list that removes the hosted copy, for
+	// external command uploaders. Empty otherwise.
+	DeleteCommand []string
+	// ExpiresAt is when the URL stops working. Zero when unknown, or when
+	// the URL only lives as long as the poster process.
+	ExpiresAt time.Time
+}
+
+// Uploader puts a local file somewhere Meta can fetch it from.
+//
+// Backends that serve files from the poster process itself also implement
+// io.Closer. Callers must keep them open until Meta has fetched the media
+// (the container reports FINISHED) and then close them.
+type Uploader interface {
+	// Name returns the backend name used in config and --uploader.
+	Name() string
+	Upload(ctx context.Context, path string) (Hosted, error)
+}
+
+// Deleter is implemented by backends that can remove a hosted copy once Meta
+// has fetched it. Deleting a Hosted without a handle, or one that is already
+// gone, is not an error.
+type Deleter interface {
+	Delete(ctx context.Context, hosted Hosted) error
+}
+
+// Identifier is implemented by backends whose hosted copies depend on more
+// than the backend name, such as the bucket. The upload cache keeps entries
+// for different identities apart, so a hit never returns a URL or delete
+// handle from another bucket.
+type Identifier interface {
+	Identity() string
+}
+
+// identityOf returns u's identity, or "" for backends without one.
+func identityOf(u Uploader) string {
+	if identifier, ok := u.(Identifier); ok {
+		return identifier.Identity()
+	}
+
+	return ""
+}
+
+const BackendUguu = "uguu"
+
+// DefaultBackend is used when no backend is configured.
+const DefaultBackend = BackendUguu
+
+// contentTypeFor returns the MIME type to serve path with: the one sniffed
+// from its content for media files, so renamed and extension-less files are
+// labelled correctly, or else the one implied by its extension.
+func contentTypeFor(path string) string {
+	if info, err := media.DetectFile(path); err == nil {
+		return info.MIMEType
+	}
+
+	return mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+}
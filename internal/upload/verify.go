@@ -0,0 +1,196 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mahmoudashraf93/poster/internal/media"
+)
+
+// Size limits Meta applies when fetching media.
+const (
+	MaxImageBytes = media.MaxImageBytes
+	MaxVideoBytes = 1 << 30
+)
+
+const verifyTimeout = 20 * time.Second
+
+// Content types Meta accepts when creating containers. Images have to be
+// JPEG; other formats are converted before local files are uploaded.
+var (
+	imageContentTypes = []string{"image/jpeg"}
+	videoContentTypes = []string{"video/mp4", "video/quicktime"}
+)
+
+// URLInfo is what a media URL reported about itself.
+type URLInfo struct {
+	ContentType string
+	// Size is the file size in bytes, or -1 when the server did not say.
+	Size int64
+}
+
+// Verifier checks that a media URL is something Meta can fetch: it answers
+// 2xx without redirecting, with a supported Content-Type and an acceptable
+// size.
+type Verifier struct {
+	HTTPClient *http.Client
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{HTTPClient: http.DefaultClient}
+}
+
+// Verify checks that rawURL is https, probes it and checks what it reports.
+func (v *Verifier) Verify(ctx context.Context, rawURL string, isVideo bool) (URLInfo, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return URLInfo{}, fmt.Errorf("%w: %w", ErrUploadInvalidURL, err)
+	}
+
+	if parsed.Scheme != "https" {
+		return URLInfo{}, fmt.Errorf("%w: %s", ErrInvalidURLScheme, parsed.Scheme)
+	}
+
+	info, err := v.Probe(ctx, rawURL)
+	if err != nil {
+		return URLInfo{}, err
+	}
+
+	return info, v.Check(info, isVideo)
+}
+
+// Probe fetches what rawURL reports about itself with a HEAD request,
+// falling back to a ranged GET for servers that do not support HEAD
+// (presigned URLs, for example, are only valid for GET). Redirects are
+// failures, since Meta does not follow them reliably.
+func (v *Verifier) Probe(ctx context.Context, rawURL string) (URLInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	info, err := v.probe(ctx, http.MethodHead, rawURL)
+	var redirectErr *redirectError
+	if err != nil && !errors.As(err, &redirectErr) {
+		info, err = v.probe(ctx, http.MethodGet, rawURL)
+	}
+
+	return info, err
+}
+
+// Check applies the content type and size rules to what a probe returned.
+func (v *Verifier) Check(info URLInfo, isVideo bool) error {
+	if err := checkContentType(info.ContentType, isVideo); err != nil {
+		return err
+	}
+
+	limit := int64(MaxImageBytes)
+	if isVideo {
+		limit = MaxVideoBytes
+	}
+
+	if info.Size > limit {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrURLTooLarge, info.Size, limit)
+	}
+
+	return nil
+}
+
+type redirectError struct {
+	status   int
+	location string
+}
+
+func (e *redirectError) Error() string {
+	return fmt.Sprintf("%s: status %d to %s", ErrURLRedirect, e.status, e.location)
+}
+
+func (e *redirectError) Unwrap() error {
+	return ErrURLRedirect
+}
+
+func (v *Verifier) probe(ctx context.Context, method, rawURL string) (URLInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return URLInfo{}, fmt.Errorf("create request: %w", err)
+	}
+
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	// Meta does not follow redirects reliably, so treat them as failures.
+	client := *v.HTTPClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return URLInfo{}, fmt.Errorf("%w: %w", ErrURLUnreachable, err)
+	}
+
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1024))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
+		return URLInfo{}, &redirectError{status: resp.StatusCode, location: resp.Header.Get("Location")}
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return URLInfo{}, fmt.Errorf("%w: %s", ErrURLStatus, resp.Status)
+	}
+
+	info := URLInfo{ContentType: resp.Header.Get("Content-Type"), Size: -1}
+	if resp.StatusCode == http.StatusPartialContent {
+		info.Size = contentRangeTotal(resp.Header.Get("Content-Range"))
+	} else if resp.ContentLength >= 0 {
+		info.Size = resp.ContentLength
+	}
+
+	return info, nil
+}
+
+// contentRangeTotal parses the total from "bytes 0-0/12345", or returns -1.
+func contentRangeTotal(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return size
+}
+
+func checkContentType(contentType string, isVideo bool) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrURLContentType, contentType)
+	}
+
+	allowed := imageContentTypes
+	kind := "a JPEG image"
+	if isVideo {
+		allowed = videoContentTypes
+		kind = "a video"
+	}
+
+	if !slices.Contains(allowed, mediaType) {
+		return fmt.Errorf("%w: %s (expected %s: %s)", ErrURLContentType, mediaType, kind, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestVerifier(server *httptest.Server) *Verifier {
+	return &Verifier{HTTPClient: server.Client()}
+}
+
+func TestVerifyAcceptsImageFromHEAD(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Fatalf("expected HEAD, got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Content-Length", "2048")
+	}))
+	defer server.Close()
+
+	info, err := newTestVerifier(server).Verify(context.Background(), server.URL+"/photo.jpg", false)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if info.ContentType != "image/jpeg" || info.Size != 2048 {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+}
+
+func TestVerifyFallsBackToRangedGET(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.Header.Get("Range") != "bytes=0-0" {
+			t.Fatalf("expected ranged GET, got %q", r.Header.Get("Range"))
+		}
+
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Range", "bytes 0-0/5000")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte{0})
+	}))
+	defer server.Close()
+
+	info, err := newTestVerifier(server).Verify(context.Background(), server.URL+"/clip.mp4", true)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if info.Size != 5000 {
+		t.Fatalf("expected size from Content-Range, got %d", info.Size)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		isVideo bool
+		handler http.HandlerFunc
+		want    error
+	}{
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			want: ErrURLStatus,
+		},
+		{
+			name: "redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/elsewhere.jpg", http.StatusFound)
+			},
+			want: ErrURLRedirect,
+		},
+		{
+			name: "html page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			},
+			want: ErrURLContentType,
+		},
+		{
+			name: "png image",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+			},
+			want: ErrURLContentType,
+		},
+		{
+			name:    "image for video",
+			isVideo: true,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+			},
+			want: ErrURLContentType,
+		},
+		{
+			name: "too large",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Header().Set("Content-Length", "9000000")
+			},
+			want: ErrURLTooLarge,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(tc.handler)
+			defer server.Close()
+
+			_, err := newTestVerifier(server).Verify(context.Background(), server.URL+"/media", tc.isVideo)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsNonHTTPS(t *testing.T) {
+	_, err := NewVerifier().Verify(context.Background(), "http://example.com/photo.jpg", false)
+	if !errors.Is(err, ErrInvalidURLScheme) {
+		t.Fatalf("expected ErrInvalidURLScheme, got %v", err)
+	}
+}